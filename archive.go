@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// archiveFileName is the file archived tasks are moved to, next to the
+// tasks file
+const archiveFileName = "archive.json"
+
+// errNotArchived is returned when unarchiving a task that isn't in the
+// archive
+var errNotArchived = errors.New("task is not in the archive")
+
+// archivePath returns the path of the archive file
+func (s *TaskStore) archivePath() string {
+	return filepath.Join(s.Dir(), archiveFileName)
+}
+
+// readArchive reads the archived tasks. A missing archive is empty.
+func (s *TaskStore) readArchive() ([]Task, error) {
+	tasks, err := readTasksFile(s.archivePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return tasks, err
+}
+
+// Archived returns the archived tasks, oldest archived first
+func (s *TaskStore) Archived() ([]Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.readArchive()
+}
+
+// Archive moves a task out of the task list into the archive file
+func (s *TaskStore) Archive(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findTaskIndex(id) == -1 {
+		return fmt.Errorf("%w: %s", errTaskNotFound, id)
+	}
+
+	_, err := s.archiveMatching(func(task Task) bool { return task.ID == id })
+	return err
+}
+
+// ArchiveDoneBefore moves every task completed before cutoff to the
+// archive and returns how many it moved
+func (s *TaskStore) ArchiveDoneBefore(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.archiveMatching(func(task Task) bool {
+		done, ok := completedAt(task)
+		return ok && done.Before(cutoff)
+	})
+}
+
+// archiveMatching moves the tasks match selects to the archive, writing
+// each file once, and returns how many it moved. s.mu must be held.
+func (s *TaskStore) archiveMatching(match func(Task) bool) (int, error) {
+	var moved, kept []Task
+	for _, task := range s.tasks {
+		if match(task) {
+			moved = append(moved, task)
+		} else {
+			kept = append(kept, task)
+		}
+	}
+	if len(moved) == 0 {
+		return 0, nil
+	}
+
+	archived, err := s.readArchive()
+	if err != nil {
+		return 0, fmt.Errorf("reading archive: %w", err)
+	}
+	now := time.Now()
+	for i := range moved {
+		moved[i].UpdatedAt = now
+	}
+	if err := writeTasksFile(s.archivePath(), append(slices.Clip(archived), moved...), s.minified); err != nil {
+		return 0, fmt.Errorf("writing archive: %w", err)
+	}
+
+	previous := s.tasks
+	s.tasks = kept
+	if s.tasks == nil {
+		s.tasks = []Task{}
+	}
+	if err := s.save(); err != nil {
+		// Put the tasks back in both places rather than lose or duplicate them
+		s.tasks = previous
+		_ = writeTasksFile(s.archivePath(), archived, s.minified)
+		return 0, err
+	}
+	return len(moved), nil
+}
+
+// Unarchive moves a task from the archive file back into the task list,
+// updating nothing but its UpdatedAt. A task whose number was taken while
+// it was archived gets the next free one.
+func (s *TaskStore) Unarchive(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	archived, err := s.readArchive()
+	if err != nil {
+		return fmt.Errorf("reading archive: %w", err)
+	}
+	idx := -1
+	for i, task := range archived {
+		if task.ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("%w: %s", errNotArchived, id)
+	}
+	if s.findTaskIndex(id) != -1 {
+		return fmt.Errorf("task %s is already in the task list", id)
+	}
+
+	task := archived[idx]
+	task.UpdatedAt = time.Now()
+	for _, existing := range s.tasks {
+		if existing.Seq == task.Seq {
+			task.Seq = s.nextSeq()
+			break
+		}
+	}
+
+	previous := s.tasks
+	s.tasks = append(slices.Clone(s.tasks), task)
+	if err := s.save(); err != nil {
+		s.tasks = previous
+		return err
+	}
+
+	remaining := append(archived[:idx:idx], archived[idx+1:]...)
+	if err := writeTasksFile(s.archivePath(), remaining, s.minified); err != nil {
+		// Leave the task archived rather than in both places
+		s.tasks = previous
+		_ = s.save()
+		return fmt.Errorf("writing archive: %w", err)
+	}
+	return nil
+}
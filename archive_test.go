@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTaskStore_ArchiveAndUnarchive(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"Write report", "Buy milk"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	original := store.GetAll()[0]
+
+	if err := store.Archive(original.ID); err != nil {
+		t.Fatalf("Failed to archive task: %v", err)
+	}
+	if tasks := store.GetAll(); len(tasks) != 1 || tasks[0].Description != "Buy milk" {
+		t.Fatalf("Expected only the other task left, got %+v", tasks)
+	}
+	archived, err := store.Archived()
+	if err != nil || len(archived) != 1 || archived[0].ID != original.ID {
+		t.Fatalf("Expected the task in the archive, got %+v, %v", archived, err)
+	}
+
+	if err := store.Unarchive(original.ID); err != nil {
+		t.Fatalf("Failed to unarchive task: %v", err)
+	}
+	if archived, _ := store.Archived(); len(archived) != 0 {
+		t.Errorf("Expected an empty archive, got %+v", archived)
+	}
+
+	// Reload to check both files on disk
+	reloaded := &TaskStore{filepath: store.filepath, tasks: []Task{}}
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	var restored *Task
+	for _, task := range reloaded.GetAll() {
+		if task.ID == original.ID {
+			restored = &task
+		}
+	}
+	if restored == nil {
+		t.Fatal("Expected the unarchived task back in the task list")
+	}
+	if restored.Description != original.Description || restored.Seq != original.Seq || !restored.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("Expected the task unchanged, got %+v want %+v", *restored, original)
+	}
+	if !restored.UpdatedAt.After(original.UpdatedAt) {
+		t.Error("Expected UpdatedAt to be refreshed")
+	}
+}
+
+func TestTaskStore_UnarchiveMissing(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if err := store.Unarchive("nope"); !errors.Is(err, errNotArchived) {
+		t.Errorf("Expected errNotArchived, got %v", err)
+	}
+}
+
+func TestTaskStore_UnarchiveRenumbersTakenSeq(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if err := store.Add("Old", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	old := store.GetAll()[0]
+	if err := store.Archive(old.ID); err != nil {
+		t.Fatalf("Failed to archive task: %v", err)
+	}
+	// With the list empty, the next task reuses number 1
+	if err := store.Add("New", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	if err := store.Unarchive(old.ID); err != nil {
+		t.Fatalf("Failed to unarchive task: %v", err)
+	}
+	tasks := store.GetAll()
+	if len(tasks) != 2 || tasks[0].Seq == tasks[1].Seq {
+		t.Errorf("Expected distinct task numbers, got %+v", tasks)
+	}
+}
+
+func TestTaskStore_ArchiveDoneBefore(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	now := time.Now()
+	old := now.AddDate(0, 0, -40)
+	recent := now.AddDate(0, 0, -2)
+	cutoff := now.AddDate(0, 0, -30)
+	store.tasks = []Task{
+		{ID: "old-done", Seq: 1, Status: StatusDone, UpdatedAt: old},
+		{ID: "recent-done", Seq: 2, Status: StatusDone, UpdatedAt: recent},
+		{ID: "old-pending", Seq: 3, Status: StatusPending, UpdatedAt: old},
+		// Edited lately, but completed long ago according to its history
+		{ID: "old-done-edited", Seq: 4, Status: StatusDone, UpdatedAt: recent,
+			History: []StatusChange{{From: StatusPending, To: StatusDone, At: old}}},
+	}
+
+	count, err := store.ArchiveDoneBefore(cutoff)
+	if err != nil {
+		t.Fatalf("ArchiveDoneBefore failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 tasks archived, got %d", count)
+	}
+
+	var left []string
+	for _, task := range store.GetAll() {
+		left = append(left, task.ID)
+	}
+	if !slices.Equal(left, []string{"recent-done", "old-pending"}) {
+		t.Errorf("Expected the recent and unfinished tasks kept, got %v", left)
+	}
+	archived, err := store.Archived()
+	if err != nil || len(archived) != 2 || archived[0].ID != "old-done" || archived[1].ID != "old-done-edited" {
+		t.Errorf("Expected the old done tasks in the archive, got %+v, %v", archived, err)
+	}
+
+	// Nothing left to archive
+	if count, err := store.ArchiveDoneBefore(cutoff); err != nil || count != 0 {
+		t.Errorf("Expected nothing archived the second time, got %d, %v", count, err)
+	}
+}
@@ -0,0 +1,976 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openStore opens the task store used by CLI subcommands; tests replace it
+var openStore = openConfiguredStore
+
+// openConfiguredStore loads the config and opens the store it describes,
+// writing any problems the load repaired to stderr
+func openConfiguredStore(stderr io.Writer) (*TaskStore, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	store, err := NewTaskStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range store.LoadWarnings() {
+		fmt.Fprintln(stderr, warning)
+	}
+	return store, nil
+}
+
+// Exit codes returned by CLI subcommands, for scripts to tell failures apart
+const (
+	exitOK       = 0
+	exitError    = 1 // the command failed
+	exitUsage    = 2 // bad arguments or flags
+	exitNotFound = 3 // no task matches the given ID
+)
+
+// lookupExitCode returns the exit code for a failed task lookup
+func lookupExitCode(err error) int {
+	if errors.Is(err, errTaskNotFound) || errors.Is(err, errNotArchived) {
+		return exitNotFound
+	}
+	return exitError
+}
+
+// runCommand dispatches a CLI subcommand and returns the process exit code
+func runCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "add":
+		return runAdd(args[1:], stdin, stdout, stderr)
+	case "import":
+		return runImport(args[1:], stdin, stdout, stderr)
+	case "export":
+		return runExport(args[1:], stdout, stderr)
+	case "rollover":
+		return runRollover(args[1:], stdout, stderr)
+	case "serve":
+		return runServe(args[1:], stderr)
+	case "stats":
+		return runStats(args[1:], stdout, stderr)
+	case "list":
+		return runList(args[1:], stdout, stderr)
+	case "categories":
+		return runCategories(args[1:], stdout, stderr)
+	case "search":
+		return runSearch(args[1:], stdout, stderr)
+	case "complete":
+		return runComplete(args[1:], stdin, stdout, stderr)
+	case "delete":
+		return runDelete(args[1:], stdin, stdout, stderr)
+	case "merge":
+		return runMerge(args[1:], stdout, stderr)
+	case "diff":
+		return runDiff(args[1:], stdout, stderr)
+	case "category-to-tag":
+		return runCategoryToTag(args[1:], stdout, stderr)
+	case "show":
+		return runShow(args[1:], stdout, stderr)
+	case "done":
+		return runDone(args[1:], stdout, stderr)
+	case "status":
+		return runStatus(args[1:], stdout, stderr)
+	case "where":
+		return runWhere(args[1:], stdout, stderr)
+	case "init":
+		return runInit(args[1:], stdout, stderr)
+	case "archive":
+		return runArchive(args[1:], stdout, stderr)
+	case "archived":
+		return runArchived(args[1:], stdout, stderr)
+	case "unarchive":
+		return runUnarchive(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "Unknown command: %s\n", args[0])
+		return exitUsage
+	}
+}
+
+// runAdd creates a task from its arguments, or one task per stdin line
+// when the description is "-"
+func runAdd(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	category := fs.String("category", "", "category for the new tasks")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	description := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if description == "" {
+		fmt.Fprintln(stderr, "Usage: patodo add [--category name] <description | ->")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	if description == "-" {
+		count, err := store.ImportLines(stdin, TaskCategory(*category))
+		if err != nil {
+			fmt.Fprintf(stderr, "Error adding tasks: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintf(stdout, "Added %d tasks\n", count)
+		return exitOK
+	}
+
+	if err := store.Add(description, TaskCategory(*category)); err != nil {
+		fmt.Fprintf(stderr, "Error adding task: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "Added: %s\n", description)
+	return exitOK
+}
+
+// runImport creates tasks from a file, or stdin when the file is "-" or
+// omitted
+func runImport(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "lines", "input format: lines or csv")
+	category := fs.String("category", "", "category for imported lines")
+	dedupeBy := fs.String("dedupe-by", "", "skip incoming tasks that duplicate one by this field: description")
+	scope := fs.String("scope", "all", "where --dedupe-by looks for duplicates: all or category")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *format != "lines" && *format != "csv" {
+		fmt.Fprintf(stderr, "Unknown format: %s\n", *format)
+		return exitUsage
+	}
+
+	dedupe := dedupeNone
+	switch *dedupeBy {
+	case "":
+	case "description":
+		dedupe = dedupeAll
+	default:
+		fmt.Fprintf(stderr, "Unknown dedupe field: %s\n", *dedupeBy)
+		return exitUsage
+	}
+	switch *scope {
+	case "all":
+	case "category":
+		if dedupe != dedupeNone {
+			dedupe = dedupeCategory
+		}
+	default:
+		fmt.Fprintf(stderr, "Unknown scope: %s\n", *scope)
+		return exitUsage
+	}
+
+	input := stdin
+	if path := fs.Arg(0); path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error opening file: %v\n", err)
+			return exitError
+		}
+		defer f.Close()
+		input = f
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	var count, skipped int
+	if *format == "csv" {
+		count, skipped, err = store.importCSV(input, stderr, dedupe)
+	} else {
+		count, skipped, err = store.importLines(input, TaskCategory(*category), dedupe)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error importing tasks: %v\n", err)
+		return exitError
+	}
+	if dedupe != dedupeNone {
+		fmt.Fprintf(stdout, "Imported %d tasks, skipped %d duplicates\n", count, skipped)
+		return exitOK
+	}
+	fmt.Fprintf(stdout, "Imported %d tasks\n", count)
+	return exitOK
+}
+
+// runExport writes all tasks to stdout
+func runExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "csv", "output format: csv")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *format != "csv" {
+		fmt.Fprintf(stderr, "Unknown format: %s\n", *format)
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	if err := store.ExportCSV(stdout); err != nil {
+		fmt.Fprintf(stderr, "Error exporting tasks: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// runRollover moves overdue unfinished tasks to today
+func runRollover(args []string, stdout, stderr io.Writer) int {
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	now := time.Now()
+	for _, task := range store.GetAll() {
+		if isOverdue(task, now) {
+			fmt.Fprintf(stdout, "%s: %s -> %s\n", task.Description, task.DueDate.Format(time.DateOnly), now.Format(time.DateOnly))
+		}
+	}
+
+	count, err := store.Rollover(now)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error rolling over tasks: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "Rolled over %d tasks\n", count)
+	return exitOK
+}
+
+// runServe starts the HTTP server until it fails
+func runServe(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	fmt.Fprintf(stderr, "Serving tasks on %s\n", *addr)
+	if err := serve(*addr, store); err != nil {
+		fmt.Fprintf(stderr, "Error running server: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// runStats prints completion metrics for all tasks
+func runStats(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "print stats as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	stats := computeStats(store.GetAll())
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(stats); err != nil {
+			fmt.Fprintf(stderr, "Error encoding stats: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	fmt.Fprint(stdout, formatStats(stats))
+	return exitOK
+}
+
+// runList prints the tasks matching the given filters
+func runList(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	status := fs.String("status", "", "only tasks with this status")
+	category := fs.String("category", "", "only tasks in this category")
+	updatedSince := fs.String("updated-since", "", "only tasks updated within this period, e.g. 7d or 24h")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	var opts FilterOptions
+	if *status != "" {
+		s := TaskStatus(*status)
+		if !isValidStatus(s) {
+			fmt.Fprintf(stderr, "Invalid status: %s\n", *status)
+			return exitUsage
+		}
+		opts.Status = &s
+	}
+	if *category != "" {
+		c := TaskCategory(*category)
+		opts.Category = &c
+	}
+	if *updatedSince != "" {
+		d, err := parseRelativeDuration(*updatedSince)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		after := time.Now().Add(-d)
+		opts.UpdatedAfter = &after
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	// The renderer only emits colors when stdout is a terminal, so piped
+	// output stays plain for grep, fzf and friends
+	renderer := lipgloss.NewRenderer(stdout)
+	for _, task := range store.Filter(opts) {
+		fmt.Fprintln(stdout, styledTaskLine(renderer, task))
+	}
+	return exitOK
+}
+
+// styledTaskLine is taskLine with the status in its color, when the
+// renderer supports colors
+func styledTaskLine(r *lipgloss.Renderer, task Task) string {
+	status := r.NewStyle().Foreground(lipgloss.Color(statusColor(task.Status)))
+	return formatTaskLine(status.Render(fmt.Sprintf("[%s]", task.Status)), task)
+}
+
+// categoryCount is a category and how many tasks are in it, as printed
+// with --json
+type categoryCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// runCategories prints the categories in use, sorted, one per line
+func runCategories(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("categories", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	withCounts := fs.Bool("counts", false, "show how many tasks are in each category")
+	asJSON := fs.Bool("json", false, "print categories and counts as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	counts := make(map[string]int)
+	for _, task := range store.GetAll() {
+		counts[string(task.Category)]++
+	}
+	categories := store.GetCategories()
+	results := make([]categoryCount, len(categories))
+	for i, category := range categories {
+		results[i] = categoryCount{Name: category, Count: counts[category]}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintf(stderr, "Error encoding categories: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	for _, result := range results {
+		if *withCounts {
+			fmt.Fprintf(stdout, "%s\t%d\n", result.Name, result.Count)
+		} else {
+			fmt.Fprintln(stdout, result.Name)
+		}
+	}
+	return exitOK
+}
+
+// taskLine formats a task as a single line of CLI output
+func taskLine(task Task) string {
+	return formatTaskLine(fmt.Sprintf("[%s]", task.Status), task)
+}
+
+// formatTaskLine formats a task line after an already formatted status
+func formatTaskLine(status string, task Task) string {
+	line := status + " " + task.Description
+	if task.Category != "" {
+		line += fmt.Sprintf(" (%s)", task.Category)
+	}
+	return line
+}
+
+// searchResult is a task matched by search, as printed with --json
+type searchResult struct {
+	Task
+	Matched string `json:"matched"` // field the query was found in
+}
+
+// runSearch prints the tasks matching a query, using the same matching
+// as the TUI search
+func runSearch(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	allFields := fs.Bool("all", false, "also search notes and category")
+	asJSON := fs.Bool("json", false, "print matches as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" {
+		fmt.Fprintln(stderr, "Usage: patodo search [--all] [--json] <query>")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	matches := store.Filter(FilterOptions{Query: query, QueryAllFields: *allFields})
+	if *asJSON {
+		results := make([]searchResult, len(matches))
+		for i, task := range matches {
+			field, _ := matchQuery(task, query, *allFields)
+			results[i] = searchResult{Task: task, Matched: field}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintf(stderr, "Error encoding tasks: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+	if len(matches) == 0 {
+		fmt.Fprintf(stderr, "No tasks match %q\n", query)
+		return exitOK
+	}
+
+	for _, task := range matches {
+		line := taskLine(task)
+		if field, _ := matchQuery(task, query, *allFields); field != fieldDescription {
+			line += fmt.Sprintf(" - matched %s", field)
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return exitOK
+}
+
+// runComplete marks every unfinished task in a category as done after
+// asking for confirmation on stdin, unless --yes is given
+func runComplete(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("complete", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	category := fs.String("category", "", "category to complete")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *category == "" {
+		fmt.Fprintln(stderr, "Usage: patodo complete --category name [--yes]")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	cat := TaskCategory(*category)
+	pending := 0
+	for _, task := range store.Filter(FilterOptions{Category: &cat}) {
+		if task.Status != StatusDone {
+			pending++
+		}
+	}
+	if pending == 0 {
+		fmt.Fprintf(stdout, "No unfinished tasks in %s\n", cat)
+		return exitOK
+	}
+
+	if !*yes {
+		fmt.Fprintf(stdout, "Mark %d tasks in %s as done? [y/N] ", pending, cat)
+		answer, _ := bufio.NewReader(stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Fprintln(stdout, "Cancelled")
+			return exitError
+		}
+	}
+
+	count, err := store.CompleteCategory(cat)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error completing tasks: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "Marked %d tasks in %s as done\n", count, cat)
+	return exitOK
+}
+
+// runDelete deletes every task matching the filter flags in one save. With
+// no filter it refuses to run unless --all is given, so a typo can't wipe
+// the list.
+func runDelete(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	status := fs.String("status", "", "only tasks with this status")
+	category := fs.String("category", "", "only tasks in this category")
+	all := fs.Bool("all", false, "delete every task when no filter is given")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	dryRun := fs.Bool("dry-run", false, "list the tasks that would be deleted")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "Usage: patodo delete [--status s] [--category name] [--all] [--yes] [--dry-run]")
+		return exitUsage
+	}
+
+	var opts FilterOptions
+	if *status != "" {
+		s := TaskStatus(*status)
+		if !isValidStatus(s) {
+			fmt.Fprintf(stderr, "Invalid status: %s\n", *status)
+			return exitUsage
+		}
+		opts.Status = &s
+	}
+	if *category != "" {
+		c := TaskCategory(*category)
+		opts.Category = &c
+	}
+	if opts.Status == nil && opts.Category == nil && !*all {
+		fmt.Fprintln(stderr, "Refusing to delete every task: pass --status or --category, or --all to mean it")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	matches := store.Filter(opts)
+	if len(matches) == 0 {
+		fmt.Fprintln(stdout, "No matching tasks")
+		return exitOK
+	}
+
+	if *dryRun {
+		fmt.Fprintf(stdout, "Would delete %d tasks:\n", len(matches))
+		for _, task := range matches {
+			fmt.Fprintf(stdout, "  %s\n", taskLine(task))
+		}
+		return exitOK
+	}
+
+	if !*yes {
+		fmt.Fprintf(stdout, "Delete %d tasks? [y/N] ", len(matches))
+		answer, _ := bufio.NewReader(stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Fprintln(stdout, "Cancelled")
+			return exitError
+		}
+	}
+
+	ids := make([]string, len(matches))
+	for i, task := range matches {
+		ids[i] = task.ID
+	}
+	count, err := store.DeleteBatch(ids)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error deleting tasks: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "Deleted %d tasks\n", count)
+	return exitOK
+}
+
+// parseInterspersed parses flags that may appear before, between, or
+// after positional arguments, and returns the positional ones
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// runMerge writes the union of two task files, keeping the newer version
+// of tasks present in both
+func runMerge(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	out := fs.String("out", "", "file to write the merged tasks to")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(files) != 2 || *out == "" {
+		fmt.Fprintln(stderr, "Usage: patodo merge a.json b.json --out merged.json")
+		return exitUsage
+	}
+
+	var lists [2][]Task
+	for i, path := range files {
+		tasks, err := readTasksFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error reading %s: %v\n", path, err)
+			return exitError
+		}
+		lists[i] = tasks
+	}
+
+	merged, stats := mergeTasks(lists[0], lists[1])
+	if err := writeTasksFile(*out, merged, false); err != nil {
+		fmt.Fprintf(stderr, "Error writing %s: %v\n", *out, err)
+		return exitError
+	}
+
+	fmt.Fprintf(stdout, "Merged %d tasks into %s\n", len(merged), *out)
+	fmt.Fprintf(stdout, "Added: %d, updated: %d, conflicts: %d\n", stats.Added, stats.Updated, stats.Conflicts)
+	return exitOK
+}
+
+// runDiff prints the tasks added, removed and changed between two task
+// files
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "print the differences as JSON")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(files) != 2 {
+		fmt.Fprintln(stderr, "Usage: patodo diff [--json] old.json new.json")
+		return exitUsage
+	}
+
+	var lists [2][]Task
+	for i, path := range files {
+		tasks, err := readTasksFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error reading %s: %v\n", path, err)
+			return exitError
+		}
+		lists[i] = tasks
+	}
+
+	diff := diffTasks(lists[0], lists[1])
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			fmt.Fprintf(stderr, "Error encoding differences: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+	fmt.Fprint(stdout, formatDiff(diff))
+	return exitOK
+}
+
+// runCategoryToTag turns a category into a tag on each of its tasks
+func runCategoryToTag(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("category-to-tag", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	category := fs.String("category", "", "category to convert")
+	clearCategory := fs.Bool("clear", false, "remove the category from the tasks")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *category == "" {
+		fmt.Fprintln(stderr, "Usage: patodo category-to-tag --category <name> [--clear]")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	count, err := store.CategoryToTag(TaskCategory(*category), *clearCategory)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error converting category: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "Tagged %d tasks with %q\n", count, *category)
+	return exitOK
+}
+
+// runShow prints every field of the task with the given ID prefix
+func runShow(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "Usage: patodo show <id>")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	task, err := store.FindByPrefix(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return lookupExitCode(err)
+	}
+
+	fmt.Fprintf(stdout, "ID:          #%d %s (%s)\n", task.Seq, shortID(task.ID), task.ID)
+	fmt.Fprintf(stdout, "Description: %s\n", task.Description)
+	fmt.Fprintf(stdout, "Status:      %s\n", task.Status)
+	if task.Category != "" {
+		fmt.Fprintf(stdout, "Category:    %s\n", task.Category)
+	}
+	if task.DueDate != nil {
+		fmt.Fprintf(stdout, "Due:         %s\n", task.DueDate.Format(time.DateOnly))
+	}
+	if len(task.Tags) > 0 {
+		fmt.Fprintf(stdout, "Tags:        %s\n", strings.Join(task.Tags, ", "))
+	}
+	for _, link := range task.Links {
+		fmt.Fprintf(stdout, "Link:        %s\n", link)
+	}
+	if task.Notes != "" {
+		fmt.Fprintf(stdout, "Notes:       %s\n", task.Notes)
+	}
+	return exitOK
+}
+
+// runDone marks the task with the given ID prefix as done
+func runDone(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "Usage: patodo done <id>")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	task, err := store.FindByPrefix(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return lookupExitCode(err)
+	}
+	if err := store.UpdateStatus(task.ID, StatusDone); err != nil {
+		fmt.Fprintf(stderr, "Error updating task: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "Done: %s\n", task.Description)
+	return exitOK
+}
+
+// runWhere prints the directory patodo keeps its data in
+func runWhere(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintln(stderr, "Usage: patodo where")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintln(stdout, store.Dir())
+	return exitOK
+}
+
+// runInit creates a .patodo directory in the working directory, giving it
+// a task list of its own
+func runInit(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintln(stderr, "Usage: patodo init")
+		return exitUsage
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error finding the current directory: %v\n", err)
+		return exitError
+	}
+	dir := filepath.Join(cwd, localDirName)
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		fmt.Fprintf(stdout, "Already initialized: %s\n", dir)
+		return exitOK
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		fmt.Fprintf(stderr, "Error creating %s: %v\n", dir, err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "Initialized a project task list in %s\n", dir)
+	return exitOK
+}
+
+// runArchive moves the task with the given ID prefix to the archive
+func runArchive(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "Usage: patodo archive <id>")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	task, err := store.FindByPrefix(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return lookupExitCode(err)
+	}
+	if err := store.Archive(task.ID); err != nil {
+		fmt.Fprintf(stderr, "Error archiving task: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "Archived: %s\n", task.Description)
+	return exitOK
+}
+
+// runArchived lists the archived tasks with their short IDs
+func runArchived(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintln(stderr, "Usage: patodo archived")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	archived, err := store.Archived()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading archive: %v\n", err)
+		return exitError
+	}
+	for _, task := range archived {
+		fmt.Fprintf(stdout, "%s %s\n", shortID(task.ID), taskLine(task))
+	}
+	return exitOK
+}
+
+// runUnarchive moves the archived task with the given ID prefix back
+// into the task list
+func runUnarchive(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "Usage: patodo unarchive <id>")
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	archived, err := store.Archived()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading archive: %v\n", err)
+		return exitError
+	}
+	task, err := findByPrefix(archived, args[0])
+	if errors.Is(err, errTaskNotFound) {
+		err = fmt.Errorf("%w: %s (see patodo archived)", errNotArchived, args[0])
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return lookupExitCode(err)
+	}
+	if err := store.Unarchive(task.ID); err != nil {
+		fmt.Fprintf(stderr, "Error unarchiving task: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "Unarchived: %s\n", task.Description)
+	return exitOK
+}
+
+// runStatus sets the status of the task with the given ID prefix
+func runStatus(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 2 {
+		fmt.Fprintln(stderr, "Usage: patodo status <id> <pending|in-progress|done>")
+		return exitUsage
+	}
+	status := TaskStatus(args[1])
+	if !isValidStatus(status) {
+		fmt.Fprintf(stderr, "Invalid status: %s (want pending, in-progress or done)\n", args[1])
+		return exitUsage
+	}
+
+	store, err := openStore(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error initializing task store: %v\n", err)
+		return exitError
+	}
+
+	task, err := store.FindByPrefix(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return lookupExitCode(err)
+	}
+	if err := store.UpdateStatus(task.ID, status); err != nil {
+		fmt.Fprintf(stderr, "Error updating task: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stdout, "%s: %s\n", status, task.Description)
+	return exitOK
+}
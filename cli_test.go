@@ -0,0 +1,723 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCommand_Unknown(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := runCommand([]string{"bogus"}, strings.NewReader(""), &stdout, &stderr)
+	if code != exitUsage {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "Unknown command") {
+		t.Errorf("Expected unknown command message, got %q", stderr.String())
+	}
+}
+
+// useTestStore points CLI subcommands at a temporary store
+func useTestStore(t *testing.T) *TaskStore {
+	t.Helper()
+
+	store := setupTestStore(t)
+	prev := openStore
+	openStore = func(io.Writer) (*TaskStore, error) { return store, nil }
+	t.Cleanup(func() { openStore = prev })
+	return store
+}
+
+func TestRunCommand_Stats(t *testing.T) {
+	store := useTestStore(t)
+	if err := store.Add("Task 1", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"stats"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Total tasks:        1") {
+		t.Errorf("Expected total in output, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"stats", "--json"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	var stats Stats
+	if err := json.Unmarshal(stdout.Bytes(), &stats); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if stats.Total != 1 {
+		t.Errorf("Expected total 1, got %d", stats.Total)
+	}
+}
+
+func TestRunCommand_Add(t *testing.T) {
+	store := useTestStore(t)
+
+	var stdout, stderr bytes.Buffer
+	code := runCommand([]string{"add", "--category", "work", "Write", "report"}, strings.NewReader(""), &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	tasks := store.GetAll()
+	if len(tasks) != 1 || tasks[0].Description != "Write report" || tasks[0].Category != "work" {
+		t.Errorf("Expected task 'Write report' in work, got %+v", tasks)
+	}
+}
+
+func TestRunCommand_AddFromStdin(t *testing.T) {
+	store := useTestStore(t)
+
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader("Buy milk\nCall mom\n")
+	code := runCommand([]string{"add", "-"}, stdin, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Added 2 tasks") {
+		t.Errorf("Expected count in output, got %q", stdout.String())
+	}
+	if len(store.GetAll()) != 2 {
+		t.Errorf("Expected 2 tasks, got %d", len(store.GetAll()))
+	}
+}
+
+func TestRunCommand_AddMissingDescription(t *testing.T) {
+	useTestStore(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"add"}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+}
+
+func TestRunCommand_ImportCSV(t *testing.T) {
+	store := useTestStore(t)
+
+	var stdout, stderr bytes.Buffer
+	input := strings.NewReader("description,status\nFirst,done\nSecond,\n")
+	if code := runCommand([]string{"import", "--format", "csv"}, input, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Imported 2 tasks") {
+		t.Errorf("Expected import count, got %q", stdout.String())
+	}
+	if len(store.GetAll()) != 2 {
+		t.Errorf("Expected 2 tasks, got %d", len(store.GetAll()))
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"export", "--format", "csv"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "description,status,category,due_date\nFirst,done,,\n") {
+		t.Errorf("Unexpected export output:\n%s", stdout.String())
+	}
+}
+
+func TestRunCommand_Search(t *testing.T) {
+	store := useTestStore(t)
+	if err := store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := store.Add("Call accountant", "finance"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := store.UpdateNotes(store.GetAll()[1].ID, "ask about the report deadline"); err != nil {
+		t.Fatalf("Failed to update notes: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"search", "report"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if stdout.String() != "[pending] Write report (work)\n" {
+		t.Errorf("Unexpected description-only output:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"search", "--all", "report"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "[pending] Call accountant (finance) - matched notes") {
+		t.Errorf("Expected notes match in output:\n%s", stdout.String())
+	}
+}
+
+func TestRunCommand_Complete(t *testing.T) {
+	store := useTestStore(t)
+	for _, category := range []TaskCategory{"work", "work", "home"} {
+		if err := store.Add("Task", category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"complete", "--category", "work"}, strings.NewReader("n\n"), &stdout, &stderr); code != 1 {
+		t.Fatalf("Expected exit code 1 when declined, got %d", code)
+	}
+	for _, task := range store.GetAll() {
+		if task.Status == StatusDone {
+			t.Fatal("Expected no tasks completed after declining")
+		}
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"complete", "--category", "work"}, strings.NewReader("y\n"), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Marked 2 tasks in work as done") {
+		t.Errorf("Expected count in output, got %q", stdout.String())
+	}
+	for _, task := range store.GetAll() {
+		if task.Category == "home" && task.Status != StatusPending {
+			t.Errorf("Expected home task to be untouched, got %s", task.Status)
+		}
+	}
+}
+
+func TestRunCommand_Merge(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	a := []Task{{ID: "1", Description: "Old", Status: StatusPending, CreatedAt: base, UpdatedAt: base}}
+	b := []Task{
+		{ID: "1", Description: "New", Status: StatusDone, CreatedAt: base, UpdatedAt: base.Add(time.Hour)},
+		{ID: "2", Description: "Extra", Status: StatusPending, CreatedAt: base, UpdatedAt: base},
+	}
+	aPath, bPath, outPath := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json"), filepath.Join(dir, "merged.json")
+	if err := writeTasksFile(aPath, a, false); err != nil {
+		t.Fatalf("Failed to write tasks: %v", err)
+	}
+	if err := writeTasksFile(bPath, b, false); err != nil {
+		t.Fatalf("Failed to write tasks: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"merge", aPath, bPath, "--out", outPath}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Added: 1, updated: 1, conflicts: 1") {
+		t.Errorf("Expected counts in output, got %q", stdout.String())
+	}
+
+	merged, err := readTasksFile(outPath)
+	if err != nil {
+		t.Fatalf("Failed to read merged tasks: %v", err)
+	}
+	if len(merged) != 2 || merged[0].Description != "New" {
+		t.Errorf("Expected newer version and extra task, got %+v", merged)
+	}
+
+	if code := runCommand([]string{"merge", aPath}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code 2 for missing arguments, got %d", code)
+	}
+}
+
+func TestRunCommand_ShowAndDoneByPrefix(t *testing.T) {
+	store := useTestStore(t)
+	if err := store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	task := store.GetAll()[0]
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"show", shortID(task.ID)[:4]}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Description: Write report") {
+		t.Errorf("Expected task details, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"done", shortID(task.ID)}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if store.GetAll()[0].Status != StatusDone {
+		t.Error("Expected task to be marked done")
+	}
+
+	stderr.Reset()
+	if code := runCommand([]string{"show", "nope"}, strings.NewReader(""), &stdout, &stderr); code != exitNotFound {
+		t.Errorf("Expected exit code 3 for unknown ID, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "task not found") {
+		t.Errorf("Expected not found error, got %q", stderr.String())
+	}
+}
+
+func TestRunCommand_List(t *testing.T) {
+	store := useTestStore(t)
+	for _, desc := range []string{"Old report", "New report", "New chore"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	store.tasks[0].UpdatedAt = time.Now().AddDate(0, 0, -10)
+	store.tasks[2].Status = StatusDone
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"list", "--updated-since", "7d", "--status", "pending"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != "[pending] New report (work)\n" {
+		t.Errorf("Expected only the recently updated pending task, got %q", got)
+	}
+
+	stderr.Reset()
+	if code := runCommand([]string{"list", "--updated-since", "soon"}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code 2 for a bad duration, got %d", code)
+	}
+}
+
+func TestRunCommand_Status(t *testing.T) {
+	store := useTestStore(t)
+	if err := store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	task := store.GetAll()[0]
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"status", shortID(task.ID), "in-progress"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if store.GetAll()[0].Status != StatusInProgress {
+		t.Error("Expected task to be in progress")
+	}
+	if stdout.String() != "in-progress: Write report\n" {
+		t.Errorf("Unexpected output %q", stdout.String())
+	}
+
+	if code := runCommand([]string{"status", shortID(task.ID), "finished"}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code 2 for unknown status, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "Invalid status: finished") {
+		t.Errorf("Expected invalid status error, got %q", stderr.String())
+	}
+	if store.GetAll()[0].Status != StatusInProgress {
+		t.Error("Unknown status should not change the task")
+	}
+
+	stderr.Reset()
+	if code := runCommand([]string{"status", "nope", "done"}, strings.NewReader(""), &stdout, &stderr); code != exitNotFound {
+		t.Errorf("Expected exit code 3 for unknown ID, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "task not found") {
+		t.Errorf("Expected not found error, got %q", stderr.String())
+	}
+}
+
+func TestRunCommand_SearchJSONAndNoMatches(t *testing.T) {
+	store := useTestStore(t)
+	if err := store.Add("Buy groceries", "home"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := store.Add("Plan week", "Groceries"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"search", "--json", "--all", "GROCERIES"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	var results []struct {
+		Description string `json:"description"`
+		Matched     string `json:"matched"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to decode output: %v\n%s", err, stdout.String())
+	}
+	if len(results) != 2 || results[0].Matched != fieldDescription || results[1].Matched != fieldCategory {
+		t.Errorf("Unexpected results %+v", results)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := runCommand([]string{"search", "dentist"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0 with no matches, got %d", code)
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), `No tasks match "dentist"`) {
+		t.Errorf("Expected only a note on stderr, got stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"search", "--json", "dentist"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0 with no matches, got %d", code)
+	}
+	if strings.TrimSpace(stdout.String()) != "[]" {
+		t.Errorf("Expected an empty JSON array, got %q", stdout.String())
+	}
+}
+
+func TestRunCommand_Categories(t *testing.T) {
+	store := useTestStore(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"categories"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected no output without categories, got:\n%s", stdout.String())
+	}
+
+	for _, task := range []struct{ desc, category string }{
+		{"Write report", "work"},
+		{"Buy milk", "home"},
+		{"Review PR", "work"},
+		{"Call mom", ""},
+	} {
+		if err := store.Add(task.desc, TaskCategory(task.category)); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"categories"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != "home\nwork\n" {
+		t.Errorf("Expected sorted categories, got:\n%s", got)
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"categories", "--counts"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != "home\t1\nwork\t2\n" {
+		t.Errorf("Expected categories with counts, got:\n%s", got)
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"categories", "--json"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	var results []categoryCount
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if len(results) != 2 || results[0] != (categoryCount{"home", 1}) || results[1] != (categoryCount{"work", 2}) {
+		t.Errorf("Unexpected JSON categories: %+v", results)
+	}
+}
+
+func TestRunCommand_ArchiveAndUnarchive(t *testing.T) {
+	store := useTestStore(t)
+	if err := store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	short := shortID(store.GetAll()[0].ID)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"archive", "#1"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if len(store.GetAll()) != 0 {
+		t.Fatal("Expected the task to leave the task list")
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"archived"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != short+" [pending] Write report (work)\n" {
+		t.Errorf("Unexpected archived listing: %q", got)
+	}
+
+	stderr.Reset()
+	if code := runCommand([]string{"unarchive", "zzzzzz"}, strings.NewReader(""), &stdout, &stderr); code != exitNotFound {
+		t.Errorf("Expected exit code 3 for an unknown ID, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "task is not in the archive") {
+		t.Errorf("Expected a clear error, got %q", stderr.String())
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"unarchive", short}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Unarchived: Write report") || len(store.GetAll()) != 1 {
+		t.Errorf("Expected the task back, got %q and %d tasks", stdout.String(), len(store.GetAll()))
+	}
+}
+
+func TestRunCommand_Where(t *testing.T) {
+	store := useTestStore(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"where"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if got := stdout.String(); got != store.Dir()+"\n" {
+		t.Errorf("Expected the data directory, got %q", got)
+	}
+}
+
+func TestRunCommand_Init(t *testing.T) {
+	dir := t.TempDir()
+	prev, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(prev) })
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"init"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr.String())
+	}
+	if info, err := os.Stat(filepath.Join(dir, localDirName)); err != nil || !info.IsDir() {
+		t.Fatalf("Expected a %s directory, got %v", localDirName, err)
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"init"}, strings.NewReader(""), &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), "Already initialized") {
+		t.Errorf("Expected a second init to be a no-op, got %d: %q", code, stdout.String())
+	}
+
+	// Stores opened below it use the project directory
+	if err := os.Mkdir("sub", 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+	if err := os.Chdir("sub"); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	got, err := tasksDir()
+	if err != nil {
+		t.Fatalf("tasksDir failed: %v", err)
+	}
+	// Compare resolved paths, as the temp dir may sit behind a symlink
+	want, _ := filepath.EvalSymlinks(filepath.Join(dir, localDirName))
+	if resolved, _ := filepath.EvalSymlinks(got); resolved != want {
+		t.Errorf("Expected tasks in %s, got %s", want, got)
+	}
+}
+
+func TestRunCommand_ExitCodes(t *testing.T) {
+	store := useTestStore(t)
+	if err := store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := shortID(store.GetAll()[0].ID)
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"show", id}, exitOK},
+		{[]string{"list", "--status", "pending"}, exitOK},
+		{[]string{"bogus"}, exitUsage},
+		{[]string{"list", "--bogus"}, exitUsage},
+		{[]string{"list", "--status", "later"}, exitUsage},
+		{[]string{"export", "--format", "xml"}, exitUsage},
+		{[]string{"done"}, exitUsage},
+		{[]string{"show", "nope"}, exitNotFound},
+		{[]string{"done", "#99"}, exitNotFound},
+		{[]string{"archive", "nope"}, exitNotFound},
+		{[]string{"unarchive", "nope"}, exitNotFound},
+		{[]string{"complete", "--category", "work"}, exitError}, // declined
+		{[]string{"merge", "missing-a.json", "missing-b.json", "--out", "out.json"}, exitError},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := runCommand(tt.args, strings.NewReader("n\n"), &stdout, &stderr); code != tt.want {
+			t.Errorf("runCommand(%q) = %d, want %d (stderr: %s)", tt.args, code, tt.want, stderr.String())
+		}
+	}
+}
+
+func TestRunCommand_Diff(t *testing.T) {
+	dir := t.TempDir()
+	old := []Task{{ID: "1", Description: "Write report", Status: StatusPending}}
+	updated := []Task{
+		{ID: "1", Description: "Write report", Status: StatusDone},
+		{ID: "2", Description: "Buy milk", Status: StatusPending},
+	}
+	oldPath, newPath := filepath.Join(dir, "old.json"), filepath.Join(dir, "new.json")
+	if err := writeTasksFile(oldPath, old, false); err != nil {
+		t.Fatalf("Failed to write tasks: %v", err)
+	}
+	if err := writeTasksFile(newPath, updated, false); err != nil {
+		t.Fatalf("Failed to write tasks: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"diff", oldPath, newPath}, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "+ [pending] Buy milk") || !strings.Contains(stdout.String(), "Write report [pending → done]") {
+		t.Errorf("Expected the added task and transition, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"diff", "--json", oldPath, newPath}, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	var diff TaskDiff
+	if err := json.Unmarshal(stdout.Bytes(), &diff); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if len(diff.Added) != 1 || len(diff.Changed) != 1 || diff.Changed[0].Status.To != StatusDone {
+		t.Errorf("Unexpected diff: %+v", diff)
+	}
+
+	if code := runCommand([]string{"diff", oldPath}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code 2 for a missing file argument, got %d", code)
+	}
+}
+
+func TestRunCommand_ListPipedHasNoEscapeCodes(t *testing.T) {
+	store := useTestStore(t)
+	for _, desc := range []string{"Write report", "Buy milk"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := store.UpdateStatus(store.GetAll()[0].ID, StatusInProgress); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	// A buffer, like a pipe, isn't a terminal
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"list"}, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if strings.Contains(stdout.String(), "\x1b") {
+		t.Errorf("Expected no escape codes, got %q", stdout.String())
+	}
+	want := "[in-progress] Write report (work)\n[pending] Buy milk (work)\n"
+	if stdout.String() != want {
+		t.Errorf("Expected plain lines %q, got %q", want, stdout.String())
+	}
+}
+
+func TestRunCommand_Delete(t *testing.T) {
+	store := useTestStore(t)
+	for _, category := range []TaskCategory{"work", "work", "home"} {
+		if err := store.Add("Task", category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := store.GetAll()
+	for _, task := range tasks[1:] {
+		if err := store.UpdateStatus(task.ID, StatusDone); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"delete", "--status", "done", "--category", "work", "--dry-run"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Would delete 1 tasks:") || len(store.GetAll()) != 3 {
+		t.Errorf("Expected a dry run listing 1 task and deleting none, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"delete", "--status", "done"}, strings.NewReader("n\n"), &stdout, &stderr); code != 1 {
+		t.Fatalf("Expected exit code 1 when declined, got %d", code)
+	}
+	if len(store.GetAll()) != 3 {
+		t.Fatal("Expected no tasks deleted after declining")
+	}
+
+	stdout.Reset()
+	if code := runCommand([]string{"delete", "--status", "done", "--category", "work", "--yes"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Deleted 1 tasks") {
+		t.Errorf("Expected count in output, got %q", stdout.String())
+	}
+	remaining := store.GetAll()
+	if len(remaining) != 2 || remaining[0].ID != tasks[0].ID || remaining[1].ID != tasks[2].ID {
+		t.Errorf("Expected only the done work task deleted, got %+v", remaining)
+	}
+}
+
+func TestRunCommand_DeleteRequiresFilter(t *testing.T) {
+	store := useTestStore(t)
+	if err := store.Add("Task", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"delete", "--yes"}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Fatalf("Expected exit code %d without a filter, got %d", exitUsage, code)
+	}
+	if len(store.GetAll()) != 1 || !strings.Contains(stderr.String(), "--all") {
+		t.Errorf("Expected nothing deleted and a hint about --all, got %q", stderr.String())
+	}
+
+	if code := runCommand([]string{"delete", "--all", "--yes"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0 with --all, got %d: %s", code, stderr.String())
+	}
+	if len(store.GetAll()) != 0 {
+		t.Errorf("Expected every task deleted with --all, got %d left", len(store.GetAll()))
+	}
+}
+
+func TestRunCommand_ImportDedupe(t *testing.T) {
+	store := useTestStore(t)
+	if err := store.Add("Buy milk", "home"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	input := "description,category\nBuy Milk,home\nBuy milk,work\nCall mom,home\n"
+	args := []string{"import", "--format", "csv", "--dedupe-by", "description", "--scope", "category"}
+	if code := runCommand(args, strings.NewReader(input), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if stdout.String() != "Imported 2 tasks, skipped 1 duplicates\n" {
+		t.Errorf("Expected imported and skipped counts, got %q", stdout.String())
+	}
+	if len(store.GetAll()) != 3 {
+		t.Errorf("Expected 3 tasks, got %d", len(store.GetAll()))
+	}
+
+	if code := runCommand([]string{"import", "--dedupe-by", "category"}, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+		t.Errorf("Expected exit code %d for an unknown dedupe field, got %d", exitUsage, code)
+	}
+}
+
+func TestOpenConfiguredStore_WritesLoadWarningsToStderr(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	prev, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(prev) })
+
+	dir := filepath.Join(home, ".config", "patodo")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create data dir: %v", err)
+	}
+	data := `[{"id":"a","description":"First","seq":1},{"id":"a","description":"Copy","seq":1}]`
+	if err := os.WriteFile(filepath.Join(dir, "tasks.json"), []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	if _, err := openConfiguredStore(&stderr); err != nil {
+		t.Fatalf("openConfiguredStore failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), `duplicate task ID a, giving "Copy"`) {
+		t.Errorf("Expected the duplicate ID warning, got %q", stderr.String())
+	}
+}
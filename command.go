@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// commandKind is the action a command line asks for
+type commandKind int
+
+const (
+	cmdFilter commandKind = iota // filter <all|pending|in-progress|done|actionable|@category>
+	cmdSort                      // sort [none|created|updated|due|...] [reverse]
+	cmdNew                       // new <description> [@category]
+)
+
+// command is a parsed line from the : prompt
+type command struct {
+	kind commandKind
+
+	// filter: status nil with actionable false and no category means all
+	status     *TaskStatus
+	actionable bool
+	category   *TaskCategory // filter and new
+
+	// sort: "" is insertion order
+	sortKey string
+	reverse bool
+
+	description string // new
+}
+
+// commandNames are the commands the : prompt understands, for error hints
+var commandNames = []string{"filter", "sort", "new"}
+
+// parseCommandLine parses a line typed at the : prompt. Words are split on
+// whitespace; a word starting with @ names a category.
+func parseCommandLine(line string) (command, error) {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return command{}, fmt.Errorf("empty command")
+	}
+
+	name, args := words[0], words[1:]
+	switch name {
+	case "filter":
+		return parseFilterCommand(args)
+	case "sort":
+		return parseSortCommand(args)
+	case "new":
+		return parseNewCommand(args)
+	}
+	return command{}, fmt.Errorf("unknown command %q (try %s)", name, strings.Join(commandNames, ", "))
+}
+
+func parseFilterCommand(args []string) (command, error) {
+	cmd := command{kind: cmdFilter}
+	if len(args) != 1 {
+		return cmd, fmt.Errorf("usage: filter all|pending|in-progress|done|actionable|@category")
+	}
+
+	arg := args[0]
+	switch {
+	case arg == "all":
+	case arg == "actionable":
+		cmd.actionable = true
+	case isValidStatus(TaskStatus(arg)):
+		status := TaskStatus(arg)
+		cmd.status = &status
+	case strings.HasPrefix(arg, "@") && len(arg) > 1:
+		category := TaskCategory(arg[1:])
+		cmd.category = &category
+	default:
+		return cmd, fmt.Errorf("unknown filter %q", arg)
+	}
+	return cmd, nil
+}
+
+func parseSortCommand(args []string) (command, error) {
+	cmd := command{kind: cmdSort}
+	if len(args) > 0 && args[len(args)-1] == "reverse" {
+		cmd.reverse = true
+		args = args[:len(args)-1]
+	}
+	if len(args) > 1 {
+		return cmd, fmt.Errorf("usage: sort [none|%s] [reverse]", strings.Join(sortKeys[1:], "|"))
+	}
+	if len(args) == 1 && args[0] != "none" {
+		if !slices.Contains(sortKeys, args[0]) {
+			return cmd, fmt.Errorf("unknown sort %q", args[0])
+		}
+		cmd.sortKey = args[0]
+	}
+	return cmd, nil
+}
+
+func parseNewCommand(args []string) (command, error) {
+	cmd := command{kind: cmdNew}
+	var description []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "@") && len(arg) > 1 {
+			category := TaskCategory(arg[1:])
+			cmd.category = &category
+			continue
+		}
+		description = append(description, arg)
+	}
+	cmd.description = strings.Join(description, " ")
+	if cmd.description == "" {
+		return cmd, fmt.Errorf("usage: new <description> [@category]")
+	}
+	return cmd, nil
+}
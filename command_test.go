@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	cmd, err := parseCommandLine("filter done")
+	if err != nil || cmd.kind != cmdFilter || cmd.status == nil || *cmd.status != StatusDone {
+		t.Errorf("Expected a done status filter, got %+v (%v)", cmd, err)
+	}
+
+	cmd, err = parseCommandLine("filter @work")
+	if err != nil || cmd.category == nil || *cmd.category != "work" || cmd.status != nil {
+		t.Errorf("Expected a work category filter, got %+v (%v)", cmd, err)
+	}
+
+	cmd, err = parseCommandLine("  sort due reverse ")
+	if err != nil || cmd.kind != cmdSort || cmd.sortKey != "due" || !cmd.reverse {
+		t.Errorf("Expected a reversed due sort, got %+v (%v)", cmd, err)
+	}
+
+	cmd, err = parseCommandLine("sort none")
+	if err != nil || cmd.sortKey != "" || cmd.reverse {
+		t.Errorf("Expected insertion order, got %+v (%v)", cmd, err)
+	}
+
+	cmd, err = parseCommandLine("new buy milk @work")
+	if err != nil || cmd.kind != cmdNew || cmd.description != "buy milk" || cmd.category == nil || *cmd.category != "work" {
+		t.Errorf("Expected a new task in work, got %+v (%v)", cmd, err)
+	}
+}
+
+func TestParseCommandLine_Errors(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"frobnicate",
+		"filter",
+		"filter someday",
+		"filter done pending",
+		"sort priority",
+		"sort due created",
+		"new @work",
+	} {
+		if _, err := parseCommandLine(line); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
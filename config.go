@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Config holds user preferences loaded from config.json
+type Config struct {
+	// TaskWarningThreshold is the task count above which the TUI suggests
+	// archiving. Zero disables the warning.
+	TaskWarningThreshold int `json:"task_warning_threshold"`
+
+	// StorageBackend selects how tasks are persisted: "file" rewrites
+	// tasks.json on every change, "log" appends changes to an event log.
+	StorageBackend string `json:"storage_backend"`
+
+	// StorageFormat selects the tasks file format: "json" (tasks.json) or
+	// "yaml" (tasks.yaml)
+	StorageFormat string `json:"storage_format"`
+
+	// LogCompactEvery is how many log events accumulate before the log
+	// is compacted into a snapshot.
+	LogCompactEvery int `json:"log_compact_every"`
+
+	// CompactStorage writes tasks.json without indentation, which keeps
+	// large task lists smaller
+	CompactStorage bool `json:"compact_storage"`
+
+	// CategoryColors maps category names to colors, either ANSI codes
+	// ("33"), hex ("#ff8800"), or basic names ("blue")
+	CategoryColors map[string]string `json:"category_colors"`
+
+	// ConfirmThreshold asks for confirmation before an operation that
+	// affects more than this many tasks. The default of 1 confirms only
+	// bulk operations; 0 confirms every operation.
+	ConfirmThreshold int `json:"confirm_threshold"`
+
+	// ConfirmTimeoutSeconds cancels an unanswered confirmation after this
+	// many seconds. Zero waits for an answer indefinitely.
+	ConfirmTimeoutSeconds int `json:"confirm_timeout_seconds"`
+
+	// SaveDebounceMs delays the TUI's background save by this many
+	// milliseconds after a change, so a burst of changes is written once.
+	// Zero saves right away.
+	SaveDebounceMs int `json:"save_debounce_ms"`
+
+	// ShowCreatedColumn adds a relative "Created" column to the table view.
+	// It's dropped on terminals too narrow to fit it.
+	ShowCreatedColumn bool `json:"show_created_column"`
+
+	// CompletionBell rings the terminal bell when a task is marked done
+	CompletionBell bool `json:"completion_bell"`
+
+	// JumpStatus is the status that { and } jump between in the TUI
+	JumpStatus TaskStatus `json:"jump_status"`
+
+	// DueSoonHours is how far ahead the TUI looks for tasks to list in
+	// the due-soon banner. Zero disables the banner.
+	DueSoonHours int `json:"due_soon_hours"`
+
+	// SearchAllFields makes search match notes and category as well as
+	// the description. It can also be toggled in search mode.
+	SearchAllFields bool `json:"search_all_fields"`
+
+	// SkipDuplicateCheck creates tasks without warning about an existing
+	// unfinished task with the same description and category
+	SkipDuplicateCheck bool `json:"skip_duplicate_check"`
+
+	// DefaultSort orders the task list on startup: "created", "updated",
+	// "due", "description", "status", or "category". Empty keeps
+	// insertion order.
+	DefaultSort string `json:"default_sort"`
+
+	// DefaultSortReverse reverses DefaultSort
+	DefaultSortReverse bool `json:"default_sort_reverse"`
+
+	// GroupOrder is the order of the status groups when grouping by
+	// status. It must list each status once; empty keeps in-progress,
+	// pending, done.
+	GroupOrder []TaskStatus `json:"group_order"`
+
+	// AutoArchiveDays archives tasks that have been done for longer than
+	// this many days when the TUI starts. Zero disables it.
+	AutoArchiveDays int `json:"auto_archive_days"`
+
+	// PinNextAction shows the next action (N) at the top of the list, in
+	// the pinned section
+	PinNextAction bool `json:"pin_next_action"`
+
+	// HideFutureTasks hides tasks whose start date hasn't come yet from
+	// the task list; H toggles it for the session
+	HideFutureTasks bool `json:"hide_future_tasks"`
+
+	// StalledDays is how many days a task can stay in progress without
+	// updates before it's marked as stalled. Zero disables the marker.
+	StalledDays int `json:"stalled_days"`
+
+	// StartOnEdit sets a pending task in progress when an edit to it is
+	// saved, for workflows where editing a task means starting it
+	StartOnEdit bool `json:"start_on_edit"`
+
+	// ShowHelp shows the key help block under the task list; ? toggles it
+	ShowHelp bool `json:"show_help"`
+
+	// StatusIcons replaces the icon shown for a status, e.g. "[x]" for
+	// done on fonts without the default symbols. Statuses left out keep
+	// their default icon.
+	StatusIcons map[TaskStatus]string `json:"status_icons"`
+
+	// ShowCategories shows the category column in the table view and the
+	// category label in the list view; c toggles it
+	ShowCategories bool `json:"show_categories"`
+
+	// WrapRows wraps long descriptions in the list view over several
+	// lines; off, each row is cut to one line. z toggles it.
+	WrapRows bool `json:"wrap_rows"`
+
+	// ShowMessages shows the message bar in list mode. Prompts in other
+	// modes (confirmations, the filter menu) are always shown.
+	ShowMessages bool `json:"show_messages"`
+
+	// Language selects the UI language ("en" or "es"). Empty uses the
+	// LANG environment variable, falling back to English.
+	Language string `json:"language"`
+
+	// Title is the header shown above the task list
+	Title string `json:"title"`
+
+	// EmptyMessage is shown in place of the task list when there are no
+	// tasks at all
+	EmptyMessage string `json:"empty_message"`
+
+	// OnboardingDone records that the first-run welcome was dismissed
+	OnboardingDone bool `json:"onboarding_done"`
+
+	// Snippets maps short triggers to text they expand to in the task
+	// description, e.g. "fu" to "Follow up:". A trigger expands when it's
+	// typed as a whole word followed by a space.
+	Snippets map[string]string `json:"snippets"`
+
+	// LastTaskID is the task under the cursor when patodo last quit
+	LastTaskID string `json:"last_task_id,omitempty"`
+
+	path     string          // file the config was loaded from; empty disables Save
+	fileKeys map[string]bool // keys set in that file, kept by Save
+}
+
+// stateKeys are written by patodo rather than set by the user, so Save
+// keeps them even while they hold their default
+var stateKeys = []string{"onboarding_done", "last_task_id"}
+
+// Default header and empty-list text, shown while the config leaves them
+// empty. They aren't defaults in DefaultConfig so the UI can translate
+// them.
+const (
+	defaultTitle        = "📝 patodo"
+	defaultEmptyMessage = "No tasks yet. Press 'n' to create one!"
+)
+
+// DefaultConfig returns the configuration used when no config file exists
+func DefaultConfig() Config {
+	return Config{
+		TaskWarningThreshold: 1000,
+		StorageBackend:       BackendFile,
+		StorageFormat:        FormatJSON,
+		LogCompactEvery:      100,
+		ConfirmThreshold:     1,
+		SaveDebounceMs:       500,
+		JumpStatus:           StatusInProgress,
+		DueSoonHours:         24,
+		StalledDays:          3,
+		HideFutureTasks:      true,
+		ShowHelp:             true,
+		ShowCategories:       true,
+		WrapRows:             true,
+		ShowMessages:         true,
+	}
+}
+
+// LoadConfig reads the config from the patodo data directory
+func LoadConfig() (Config, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return Config{}, err
+	}
+
+	return loadConfigFile(filepath.Join(dir, "config.json"))
+}
+
+// loadConfigFile reads the config at path, falling back to defaults for
+// a missing file or missing keys
+func loadConfigFile(path string) (Config, error) {
+	cfg := DefaultConfig()
+	cfg.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return Config{}, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, err
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return Config{}, err
+	}
+	cfg.fileKeys = make(map[string]bool, len(keys))
+	for key := range keys {
+		cfg.fileKeys[key] = true
+	}
+	return cfg, nil
+}
+
+// Save writes the config back to the file it was loaded from. Only keys
+// the file already had, keys changed from their default and stateKeys
+// are written, so a later change to a default still reaches the user.
+func (c Config) Save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	fields, err := configFields(c)
+	if err != nil {
+		return err
+	}
+	defaults, err := configFields(DefaultConfig())
+	if err != nil {
+		return err
+	}
+	for key, value := range fields {
+		if c.fileKeys[key] || slices.Contains(stateKeys, key) {
+			continue
+		}
+		if bytes.Equal(value, defaults[key]) {
+			delete(fields, key)
+		}
+	}
+
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// configFields returns c as its JSON keys and encoded values
+func configFields(c Config) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
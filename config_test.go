@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigFile_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load missing config: %v", err)
+	}
+	if cfg.TaskWarningThreshold != DefaultConfig().TaskWarningThreshold {
+		t.Errorf("Expected default threshold, got %d", cfg.TaskWarningThreshold)
+	}
+}
+
+func TestLoadConfigFile_Overrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"task_warning_threshold": 50}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.TaskWarningThreshold != 50 {
+		t.Errorf("Expected threshold 50, got %d", cfg.TaskWarningThreshold)
+	}
+}
+
+func TestConfig_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.TaskWarningThreshold = 5
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	loaded, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if loaded.TaskWarningThreshold != 5 {
+		t.Errorf("Expected threshold 5 after reload, got %d", loaded.TaskWarningThreshold)
+	}
+}
+
+func TestLoadConfigFile_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := loadConfigFile(path); err == nil {
+		t.Error("Expected error for malformed config")
+	}
+}
+
+func TestConfig_SaveKeepsTextDefaultsOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.ShowHelp = false
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	loaded, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if loaded.Title != "" || loaded.EmptyMessage != "" {
+		t.Errorf("Expected the built-in title and empty message left out of the file, got %q and %q", loaded.Title, loaded.EmptyMessage)
+	}
+}
+
+func TestConfig_SaveWritesOnlySetKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"stalled_days": 3}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.ShowHelp = false
+	cfg.OnboardingDone = true
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	var keys map[string]any
+	if err := json.Unmarshal(data, &keys); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	// A default the user never set stays out, so a new default applies
+	if _, ok := keys["due_soon_hours"]; ok {
+		t.Errorf("Expected the default due_soon_hours left out, got %s", data)
+	}
+	// The changed key, the key from the file and the state key are kept
+	for _, key := range []string{"show_help", "stalled_days", "onboarding_done"} {
+		if _, ok := keys[key]; !ok {
+			t.Errorf("Expected %s in the saved config, got %s", key, data)
+		}
+	}
+}
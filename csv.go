@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// csvColumns is the header written by ExportCSV and understood by ImportCSV
+var csvColumns = []string{"description", "status", "category", "due_date"}
+
+// ExportCSV writes all tasks to w as CSV with a header row
+func (s *TaskStore) ExportCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, task := range s.GetAll() {
+		due := ""
+		if task.DueDate != nil {
+			due = task.DueDate.Format(time.DateOnly)
+		}
+		record := []string{task.Description, string(task.Status), string(task.Category), due}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ImportCSV creates a task for each row read from r, with a single save.
+// The header row maps columns by name in any order; only description is
+// required. Warnings about unusable values are written to stderr. It
+// returns the number of tasks created.
+func (s *TaskStore) ImportCSV(r io.Reader) (int, error) {
+	count, _, err := s.importCSV(r, os.Stderr, dedupeNone)
+	return count, err
+}
+
+// importCSV is ImportCSV with warnings written to warn and duplicates
+// skipped according to dedupe. It also returns the number of rows skipped.
+func (s *TaskStore) importCSV(r io.Reader, warn io.Writer, dedupe importDedupe) (int, int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["description"]; !ok {
+		return 0, 0, fmt.Errorf("missing description column")
+	}
+
+	// field returns the named column of a record, or "" if it's absent
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var tasks []Task
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		description := field(record, "description")
+		if description == "" {
+			continue
+		}
+		task := Task{Description: description, Status: StatusPending, Category: TaskCategory(field(record, "category"))}
+
+		if status := TaskStatus(field(record, "status")); status != "" {
+			if isValidStatus(status) {
+				task.Status = status
+			} else {
+				fmt.Fprintf(warn, "Warning: line %d: unknown status %q, using pending\n", line, status)
+			}
+		}
+
+		if due := field(record, "due_date"); due != "" {
+			if date, err := time.ParseInLocation(time.DateOnly, due, time.Local); err == nil {
+				task.DueDate = &date
+			} else {
+				fmt.Fprintf(warn, "Warning: line %d: invalid due date %q, ignoring\n", line, due)
+			}
+		}
+
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		return 0, 0, nil
+	}
+
+	count, skipped := 0, 0
+	err = s.Batch(func(b *TaskBatch) error {
+		var kept []Task
+		kept, skipped = dedupe.filter(b.store.tasks, tasks)
+		for _, task := range kept {
+			added := b.Add(task.Description, task.Category)
+			b.UpdateStatus(added.ID, task.Status)
+			b.SetDueDate(added.ID, task.DueDate)
+		}
+		count = len(kept)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, skipped, nil
+}
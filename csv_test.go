@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskStore_CSVRoundTrip(t *testing.T) {
+	source := setupTestStore(t)
+	defer cleanupTestStore(source)
+
+	if err := source.Add("Write report, draft 2", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := source.Add("Buy milk", ""); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	tasks := source.GetAll()
+	if err := source.UpdateStatus(tasks[0].ID, StatusInProgress); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	due := time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local)
+	err := source.Batch(func(b *TaskBatch) error {
+		b.SetDueDate(tasks[0].ID, &due)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to set due date: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := source.ExportCSV(&buf); err != nil {
+		t.Fatalf("Failed to export CSV: %v", err)
+	}
+
+	target := setupTestStore(t)
+	defer cleanupTestStore(target)
+
+	count, err := target.ImportCSV(&buf)
+	if err != nil {
+		t.Fatalf("Failed to import CSV: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 imported tasks, got %d", count)
+	}
+
+	imported := target.GetAll()
+	if imported[0].Description != "Write report, draft 2" {
+		t.Errorf("Expected quoted description to survive, got '%s'", imported[0].Description)
+	}
+	if imported[0].Status != StatusInProgress || imported[0].Category != "work" {
+		t.Errorf("Expected in-progress work task, got %s/%s", imported[0].Status, imported[0].Category)
+	}
+	if imported[0].DueDate == nil || !imported[0].DueDate.Equal(due) {
+		t.Errorf("Expected due date %v, got %v", due, imported[0].DueDate)
+	}
+	if imported[1].DueDate != nil || imported[1].Status != StatusPending {
+		t.Errorf("Expected pending task without due date, got %+v", imported[1])
+	}
+}
+
+func TestTaskStore_ImportCSVColumnMapping(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	input := "Category,Description,Status\nhome,Water plants,someday\nwork,Ship it,done\n"
+	var warnings bytes.Buffer
+	count, _, err := store.importCSV(strings.NewReader(input), &warnings, dedupeNone)
+	if err != nil {
+		t.Fatalf("Failed to import CSV: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 imported tasks, got %d", count)
+	}
+
+	tasks := store.GetAll()
+	if tasks[0].Description != "Water plants" || tasks[0].Category != "home" {
+		t.Errorf("Expected columns mapped by header, got %+v", tasks[0])
+	}
+	if tasks[0].Status != StatusPending {
+		t.Errorf("Expected unknown status to default to pending, got %s", tasks[0].Status)
+	}
+	if !strings.Contains(warnings.String(), `unknown status "someday"`) {
+		t.Errorf("Expected unknown status warning, got %q", warnings.String())
+	}
+	if tasks[1].Status != StatusDone {
+		t.Errorf("Expected done status, got %s", tasks[1].Status)
+	}
+}
+
+func TestTaskStore_ImportCSVMissingDescription(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if _, err := store.ImportCSV(strings.NewReader("status,category\ndone,work\n")); err == nil {
+		t.Error("Expected error for CSV without description column")
+	}
+	if len(store.GetAll()) != 0 {
+		t.Error("Expected no tasks to be imported")
+	}
+}
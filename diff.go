@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldChange is a field that differs between two versions of a task
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// StatusTransition is a task's status in the old and new lists
+type StatusTransition struct {
+	From TaskStatus `json:"from"`
+	To   TaskStatus `json:"to"`
+}
+
+// TaskChange is a task present in both lists with different content. Status
+// changes are reported on their own rather than among the other fields.
+type TaskChange struct {
+	ID          string            `json:"id"`
+	Description string            `json:"description"` // as in the new list
+	Status      *StatusTransition `json:"status,omitempty"`
+	Fields      []FieldChange     `json:"fields,omitempty"`
+}
+
+// TaskDiff is what changed between two snapshots of a task list
+type TaskDiff struct {
+	Added   []Task       `json:"added"`
+	Removed []Task       `json:"removed"`
+	Changed []TaskChange `json:"changed"`
+}
+
+// Empty reports whether the two snapshots hold the same tasks
+func (d TaskDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffTasks compares two task lists by ID. Added and changed tasks follow
+// the new list's order, removed ones the old list's. Bookkeeping fields
+// (number, timestamps, history) are ignored, so a task that was only
+// renumbered or touched isn't reported.
+func diffTasks(old, new []Task) TaskDiff {
+	diff := TaskDiff{Added: []Task{}, Removed: []Task{}, Changed: []TaskChange{}}
+
+	before := make(map[string]Task, len(old))
+	for _, task := range old {
+		before[task.ID] = task
+	}
+	after := make(map[string]bool, len(new))
+	for _, task := range new {
+		after[task.ID] = true
+		prev, ok := before[task.ID]
+		if !ok {
+			diff.Added = append(diff.Added, task)
+			continue
+		}
+		if change, changed := compareTasks(prev, task); changed {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	for _, task := range old {
+		if !after[task.ID] {
+			diff.Removed = append(diff.Removed, task)
+		}
+	}
+	return diff
+}
+
+// compareTasks lists the fields that differ between two versions of a task
+func compareTasks(old, new Task) (TaskChange, bool) {
+	change := TaskChange{ID: new.ID, Description: new.Description}
+	if old.Status != new.Status {
+		change.Status = &StatusTransition{From: old.Status, To: new.Status}
+	}
+
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"description", old.Description, new.Description},
+		{"category", string(old.Category), string(new.Category)},
+		{"due", formatDue(old.DueDate), formatDue(new.DueDate)},
+		{"start", formatDue(old.StartDate), formatDue(new.StartDate)},
+		{"reminder", formatReminder(old.RemindAt), formatReminder(new.RemindAt)},
+		{"priority", old.Priority.String(), new.Priority.String()},
+		{"pinned", strconv.FormatBool(old.Pinned), strconv.FormatBool(new.Pinned)},
+		{"next action", strconv.FormatBool(old.NextAction), strconv.FormatBool(new.NextAction)},
+		{"blocked", strconv.FormatBool(old.Blocked), strconv.FormatBool(new.Blocked)},
+		{"blocked reason", old.BlockedReason, new.BlockedReason},
+		{"notes", old.Notes, new.Notes},
+		{"tags", strings.Join(old.Tags, ", "), strings.Join(new.Tags, ", ")},
+		{"links", strings.Join(old.Links, ", "), strings.Join(new.Links, ", ")},
+	}
+	for _, field := range fields {
+		if field.old != field.new {
+			change.Fields = append(change.Fields, FieldChange{Field: field.name, Old: field.old, New: field.new})
+		}
+	}
+	return change, change.Status != nil || len(change.Fields) > 0
+}
+
+// formatDue formats an optional due date for a diff
+func formatDue(due *time.Time) string {
+	if due == nil {
+		return ""
+	}
+	return due.Format(time.DateOnly)
+}
+
+// formatReminder formats an optional reminder time for a diff
+func formatReminder(at *time.Time) string {
+	if at == nil {
+		return ""
+	}
+	return at.Format("2006-01-02 15:04")
+}
+
+// formatDiff renders a diff for the terminal. Status transitions go on the
+// task's own line so progress stands out from other edits.
+func formatDiff(diff TaskDiff) string {
+	if diff.Empty() {
+		return "No changes\n"
+	}
+
+	var b strings.Builder
+	if len(diff.Added) > 0 {
+		fmt.Fprintf(&b, "Added (%d):\n", len(diff.Added))
+		for _, task := range diff.Added {
+			fmt.Fprintf(&b, "  + %s\n", taskLine(task))
+		}
+	}
+	if len(diff.Removed) > 0 {
+		fmt.Fprintf(&b, "Removed (%d):\n", len(diff.Removed))
+		for _, task := range diff.Removed {
+			fmt.Fprintf(&b, "  - %s\n", taskLine(task))
+		}
+	}
+	if len(diff.Changed) > 0 {
+		fmt.Fprintf(&b, "Changed (%d):\n", len(diff.Changed))
+		for _, change := range diff.Changed {
+			line := "  ~ " + change.Description
+			if change.Status != nil {
+				line += fmt.Sprintf(" [%s → %s]", change.Status.From, change.Status.To)
+			}
+			b.WriteString(line + "\n")
+			for _, field := range change.Fields {
+				fmt.Fprintf(&b, "      %s: %s → %s\n", field.Field, diffValue(field.Old), diffValue(field.New))
+			}
+		}
+	}
+	return b.String()
+}
+
+// diffValue shows an empty field value as a dash and quotes multi-line
+// ones (notes) so they stay on one line
+func diffValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	if strings.Contains(value, "\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffTasks(t *testing.T) {
+	base := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	due := base.AddDate(0, 0, 3)
+
+	old := []Task{
+		{ID: "1", Seq: 1, Description: "Unchanged", Status: StatusPending, UpdatedAt: base},
+		{ID: "2", Description: "Write report", Status: StatusInProgress, Category: "work"},
+		{ID: "3", Description: "Removed", Status: StatusPending},
+		{ID: "4", Description: "Call mom", Status: StatusPending},
+	}
+	updated := []Task{
+		// Renumbered and touched only: not a change
+		{ID: "1", Seq: 7, Description: "Unchanged", Status: StatusPending, UpdatedAt: base.Add(time.Hour)},
+		{ID: "5", Description: "Added", Status: StatusPending},
+		{ID: "4", Description: "Call mum", Status: StatusPending, DueDate: &due, Tags: []string{"family"}},
+		{ID: "2", Description: "Write report", Status: StatusDone, Category: "work"},
+	}
+
+	diff := diffTasks(old, updated)
+
+	if len(diff.Added) != 1 || diff.Added[0].ID != "5" {
+		t.Errorf("Expected task 5 added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != "3" {
+		t.Errorf("Expected task 3 removed, got %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 2 {
+		t.Fatalf("Expected 2 changed tasks, got %+v", diff.Changed)
+	}
+
+	renamed := diff.Changed[0]
+	if renamed.ID != "4" || renamed.Status != nil {
+		t.Errorf("Expected task 4 changed without a status change, got %+v", renamed)
+	}
+	want := []FieldChange{
+		{Field: "description", Old: "Call mom", New: "Call mum"},
+		{Field: "due", Old: "", New: "2026-05-04"},
+		{Field: "tags", Old: "", New: "family"},
+	}
+	if len(renamed.Fields) != len(want) {
+		t.Fatalf("Expected %d field changes, got %+v", len(want), renamed.Fields)
+	}
+	for i, field := range want {
+		if renamed.Fields[i] != field {
+			t.Errorf("Expected field change %+v, got %+v", field, renamed.Fields[i])
+		}
+	}
+
+	finished := diff.Changed[1]
+	if finished.Status == nil || *finished.Status != (StatusTransition{From: StatusInProgress, To: StatusDone}) {
+		t.Errorf("Expected an in-progress → done transition, got %+v", finished.Status)
+	}
+	if len(finished.Fields) != 0 {
+		t.Errorf("Expected only the status to change, got %+v", finished.Fields)
+	}
+}
+
+func TestDiffTasks_Same(t *testing.T) {
+	tasks := []Task{{ID: "1", Description: "Write report"}}
+	if diff := diffTasks(tasks, tasks); !diff.Empty() {
+		t.Errorf("Expected no differences, got %+v", diff)
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	diff := TaskDiff{
+		Added:   []Task{{Description: "Buy milk", Status: StatusPending}},
+		Removed: []Task{{Description: "Old chore", Status: StatusDone}},
+		Changed: []TaskChange{{
+			Description: "Write report",
+			Status:      &StatusTransition{From: StatusPending, To: StatusDone},
+			Fields:      []FieldChange{{Field: "category", Old: "", New: "work"}},
+		}},
+	}
+
+	out := formatDiff(diff)
+	for _, want := range []string{
+		"Added (1):\n  + [pending] Buy milk",
+		"Removed (1):\n  - [done] Old chore",
+		"  ~ Write report [pending → done]\n      category: - → work",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	if out := formatDiff(TaskDiff{}); out != "No changes\n" {
+		t.Errorf("Expected no changes, got %q", out)
+	}
+}
+
+func TestDiffTasks_NextAction(t *testing.T) {
+	old := []Task{{ID: "1", Description: "Write report", NextAction: true}, {ID: "2", Description: "Call mom"}}
+	updated := []Task{{ID: "1", Description: "Write report"}, {ID: "2", Description: "Call mom", NextAction: true}}
+
+	diff := diffTasks(old, updated)
+	if len(diff.Changed) != 2 {
+		t.Fatalf("Expected both tasks changed, got %+v", diff.Changed)
+	}
+	lost, gained := diff.Changed[0].Fields, diff.Changed[1].Fields
+	if len(lost) != 1 || lost[0] != (FieldChange{Field: "next action", Old: "true", New: "false"}) {
+		t.Errorf("Expected task 1 to lose the next action, got %+v", lost)
+	}
+	if len(gained) != 1 || gained[0] != (FieldChange{Field: "next action", Old: "false", New: "true"}) {
+		t.Errorf("Expected task 2 to gain the next action, got %+v", gained)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanizeTime(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{-time.Hour, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{3 * time.Hour, "3h ago"},
+		{2 * 24 * time.Hour, "2d ago"},
+		{15 * 24 * time.Hour, "2w ago"},
+		{90 * 24 * time.Hour, "3mo ago"},
+		{800 * 24 * time.Hour, "2y ago"},
+	}
+
+	for _, tt := range tests {
+		if got := humanizeTime(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("humanizeTime(%v ago) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestParseRelativeDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{" 1d ", 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"24h", 24 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"xd", 0, true},
+		{"0d", 0, true},
+		{"-3d", 0, true},
+		{"7 days", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseRelativeDuration(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRelativeDuration(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRelativeDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
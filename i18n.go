@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultLanguage is used when neither the config nor the environment
+// names a language with a catalog
+const defaultLanguage = "en"
+
+// catalogs holds the translations of the UI strings, keyed by language
+// and then by the English text. English has no catalog: a missing entry
+// falls back to the key itself.
+var catalogs = map[string]map[string]string{
+	"es": spanish,
+}
+
+// detectLanguage returns the configured language, or the one named by the
+// LC_ALL or LANG environment variables (e.g. "es_AR.UTF-8") when the
+// config leaves it empty. Languages without a catalog fall back to
+// English.
+func detectLanguage(configured string) string {
+	lang := configured
+	if lang == "" {
+		lang = os.Getenv("LC_ALL")
+	}
+	if lang == "" {
+		lang = os.Getenv("LANG")
+	}
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "_")
+	lang = strings.ToLower(lang)
+	if _, ok := catalogs[lang]; !ok {
+		return defaultLanguage
+	}
+	return lang
+}
+
+// translate returns key in the given language, formatted with args as by
+// fmt.Sprintf when there are any
+func translate(lang, key string, args ...any) string {
+	text := key
+	if translated, ok := catalogs[lang][key]; ok {
+		text = translated
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// t translates a UI string into the model's language
+func (m model) t(key string, args ...any) string {
+	return translate(m.lang, key, args...)
+}
+
+// statusName returns the display name of a status
+func (m model) statusName(status TaskStatus) string {
+	return m.t(string(status))
+}
+
+var spanish = map[string]string{
+	// Statuses, priorities and other values shown inside messages
+	"pending":       "pendiente",
+	"in-progress":   "en curso",
+	"done":          "hecha",
+	"none":          "ninguna",
+	"low":           "baja",
+	"medium":        "media",
+	"high":          "alta",
+	"any":           "cualquiera",
+	"overdue":       "vencidas",
+	"due today":     "vencen hoy",
+	"due this week": "vencen esta semana",
+	"actionable":    "accionables",
+	"stalled":       "estancadas",
+	"all":           "todas",
+	"yes":           "sí",
+	"no":            "no",
+	"table":         "tabla",
+	"list":          "lista",
+	"no category":   "sin categoría",
+	"description":   "descripción",
+	"notes":         "notas",
+	"category":      "categoría",
+	"new...":        "nueva...",
+
+	"updated this week": "actualizadas esta semana",
+
+	// Headers and labels
+	"Goodbye!":                             "¡Hasta luego!",
+	"Goodbye! (unsaved changes discarded)": "¡Hasta luego! (cambios sin guardar descartados)",
+	"📌 Pinned":                             "📌 Fijadas",
+	"Tasks":                                "Tareas",
+	"Pending":                              "Pendientes",
+	"In Progress":                          "En curso",
+	"Done":                                 "Hechas",
+	"ID":                                   "ID",
+	"Status":                               "Estado",
+	"Description":                          "Descripción",
+	"Category":                             "Categoría",
+	"Created":                              "Creada",
+	"Updated":                              "Actualizada",
+	"Priority":                             "Prioridad",
+	"Due":                                  "Vence",
+	"Blocked":                              "Bloqueada",
+	"Tags":                                 "Etiquetas",
+	"Notes":                                "Notas",
+	"Matched in":                           "Coincide en",
+	"Actionable":                           "Accionable",
+	"Description:":                         "Descripción:",
+	"Category:":                            "Categoría:",
+	"Blocked by:":                          "Bloqueada por:",
+	"Select category:":                     "Elige una categoría:",
+	"All categories":                       "Todas las categorías",
+	"No categories yet.":                   "Todavía no hay categorías.",
+	"Search:":                              "Buscar:",
+	"Tag:":                                 "Etiqueta:",
+	"Notes:":                               "Notas:",
+	"Pick a category:":                     "Elige una categoría:",
+	"Error opening link: %v":               "Error al abrir el enlace: %v",
+	"No links to remove":                   "No hay enlaces para quitar",
+	"No links to open":                     "No hay enlaces para abrir",
+	"Opening %s":                           "Abriendo %s",
+	"Links unchanged":                      "Enlaces sin cambios",
+	"Link cannot be empty":                 "El enlace no puede estar vacío",
+	"Error updating links: %v":             "Error al actualizar los enlaces: %v",
+	"Link removed":                         "Enlace quitado",
+	"Link added":                           "Enlace añadido",
+	"Link:":                                "Enlace:",
+	"Links":                                "Enlaces",
+	"URL or file path to add (Enter to save, ESC to cancel)":                "URL o ruta de archivo a añadir (Enter para guardar, ESC para cancelar)",
+	"Number or text of the link to remove (Enter to remove, ESC to cancel)": "Número o texto del enlace a quitar (Enter para quitar, ESC para cancelar)",
+	"[esc/enter] back  [l/L] add/remove link  [O] open first link":          "[esc/enter] volver  [l/L] añadir/quitar enlace  [O] abrir el primer enlace",
+	"Task updated and marked in progress":                                   "Tarea actualizada y marcada en curso",
+	"Type a letter to jump to the next task starting with it (ESC to stop)": "Escribe una letra para saltar a la siguiente tarea que empiece por ella (ESC para terminar)",
+	"No task starts with %q":                                                "Ninguna tarea empieza por %q",
+	"Invalid group_order (%v), using the default order":                     "group_order no válido (%v), se usa el orden predeterminado",
+	"Picked: %s":                                                "Elegida: %s",
+	"No pending tasks to pick from":                             "No hay tareas pendientes para elegir",
+	"showing %d of %d (%d filtered)":                            "mostrando %d de %d (%d filtradas)",
+	"No project task list here (run patodo init to create one)": "No hay lista de tareas del proyecto aquí (ejecuta patodo init para crearla)",
+	"Error switching task list: %v":                             "Error al cambiar de lista de tareas: %v",
+	"Switched to the project task list":                         "Cambiado a la lista de tareas del proyecto",
+	"Switched to the global task list":                          "Cambiado a la lista de tareas global",
+	"project":                                                   "proyecto",
+	"global":                                                    "global",
+	"Invalid status_icons (%v), using the default icons":        "status_icons no es válido (%v), se usan los iconos predeterminados",
+	"Task is already done":                                      "La tarea ya está hecha",
+	"Done: %s":                                                  "Hecha: %s",
+	"All done!":                                                 "¡Todo hecho!",
+	"Remind at? (2006-01-02 15:04, 2006-01-02 or 2h; empty clears)": "¿Recordar cuándo? (2006-01-02 15:04, 2006-01-02 o 2h; vacío borra)",
+	"Reminder cancelled":                         "Recordatorio cancelado",
+	"Invalid reminder time: %s":                  "Hora de recordatorio inválida: %s",
+	"Reminder cleared":                           "Recordatorio borrado",
+	"Reminder set for %s":                        "Recordatorio para %s",
+	"🔔 Reminders ([w] dismiss):":                 "🔔 Recordatorios ([w] ocultar):",
+	"Remind at:":                                 "Recordar:",
+	"Reminder":                                   "Recordatorio",
+	"Showing task IDs":                           "Mostrando los IDs de las tareas",
+	"Hiding task IDs":                            "Ocultando los IDs de las tareas",
+	"Start on? (2006-01-02 or 3d; empty clears)": "¿Empieza cuándo? (2006-01-02 o 3d; vacío borra)",
+	"Hiding tasks that haven't started":          "Ocultando las tareas que aún no empiezan",
+	"Showing tasks that haven't started":         "Mostrando las tareas que aún no empiezan",
+	"Start date cancelled":                       "Fecha de inicio cancelada",
+	"Invalid start date: %s":                     "Fecha de inicio inválida: %s",
+	"Start date cleared":                         "Fecha de inicio borrada",
+	"Starts %s":                                  "Empieza %s",
+	"%d not started":                             "%d sin empezar",
+	"Start date:":                                "Fecha de inicio:",
+	"Starts":                                     "Empieza",
+	"Long rows wrapped":                          "Filas largas ajustadas",
+	"Long rows truncated":                        "Filas largas recortadas",
+	"Command: filter, sort or new (Enter to run, ESC to cancel)": "Comando: filter, sort o new (Enter para ejecutar, ESC para cancelar)",
+	"Command:":                  "Comando:",
+	"Command cancelled":         "Comando cancelado",
+	"Command error: %v":         "Error en el comando: %v",
+	"Sorted in insertion order": "Orden de creación",
+	"Sorted by %s":              "Ordenadas por %s",
+	"Gave new IDs to %d tasks that shared an ID with another task": "Se dieron IDs nuevos a %d tareas que compartían ID con otra",
+	"Buried: %s":                  "Enterrada: %s",
+	"Next action cleared":         "Siguiente acción quitada",
+	"Next action: %s":             "Siguiente acción: %s",
+	"Error updating due date: %v": "Error al actualizar la fecha límite: %v",
+	"Due %s":                      "Vence %s",
+	"History":                     "Historial",
+	"Categories shown":            "Categorías visibles",
+	"Categories hidden":           "Categorías ocultas",
+	"Saving…":                     "Guardando…",
+	"Saved":                       "Guardado",
+	"Error saving tasks: %v":      "Error al guardar las tareas: %v",
+	"Preview:":                    "Vista previa:",
+	"Terminal too small — resize to at least %dx%d": "Terminal demasiado pequeña — amplíala al menos a %dx%d",
+	"▶ Working on: %s":                              "▶ Trabajando en: %s",
+	"? for help":                                    "? para ver la ayuda",
+	" … (%d chars)":                                 " … (%d caracteres)",
+	" (waiting on %s)":                              " (esperando a %s)",
+	" — notes: ":                                    " — notas: ",
+
+	"Enter task description...":                "Escribe la descripción de la tarea...",
+	"Enter category (work, personal, etc.)...": "Escribe la categoría (trabajo, personal, etc.)...",
+
+	"📝 patodo":                               "📝 patodo",
+	"No tasks yet. Press 'n' to create one!": "Todavía no hay tareas. ¡Pulsa 'n' para crear una!",
+	"No tasks match the current filter (press f→a to clear)": "Ninguna tarea coincide con el filtro actual (pulsa f→a para quitarlo)",
+
+	"⚠ %d tasks stored - consider archiving old tasks to keep patodo fast ([w] dismiss)": "⚠ %d tareas guardadas - considera archivar las antiguas para que patodo siga siendo rápido ([w] ocultar)",
+	"⏰ Due in the next %dh ([w] dismiss):":                                               "⏰ Vencen en las próximas %dh ([w] ocultar):",
+
+	"Welcome to patodo! 👋\n\n" +
+		"[n] create a task    [e] edit it\n" +
+		"[d] mark done        [i] in-progress\n" +
+		"[f] filter tasks     [x] delete\n" +
+		"[j/k] move around    [q] quit\n\n" +
+		"Press any key to get started.": "¡Bienvenido a patodo! 👋\n\n" +
+		"[n] crear una tarea  [e] editarla\n" +
+		"[d] marcar hecha     [i] en curso\n" +
+		"[f] filtrar tareas   [x] borrar\n" +
+		"[j/k] moverse        [q] salir\n\n" +
+		"Pulsa cualquier tecla para empezar.",
+
+	"[n] new task\n[e] edit task\n[r] rename task\n[v] toggle view (%s)\n[g] group by status\n[d] done/undone\n[F] finish and go to next\n[i] in-progress\n[p] pending\n[[/]] cycle category\n[-] clear category\n[c] show/hide categories\n[z] wrap/truncate rows\n[{/}] previous/next %s\n[0-3] priority\n[+/_] raise/lower priority\n[>/W] due a day/week later\n[*] pin/unpin\n[N] next action\n[B] bury\n[M] set reminder\n[S] start date\n[H] show/hide not started\n[b] block/unblock\n[space] select\n[a/A] add/remove tag\n[x] delete\n[C] complete category\n[o] notes\n[enter] details\n[/] search\n[R] pick a random pending task\n['] jump by first letter\n[:] command line\n[ctrl+r] reload\n[D] open data folder\n[P] project/global tasks\n[I] show IDs\n[t] cycle status filter\n[f] filter (%s)\n[backspace] previous filter\n[?] hide help\n[q] quit\n[ctrl+x] quit without saving": "[n] nueva tarea\n[e] editar tarea\n[r] renombrar tarea\n[v] cambiar vista (%s)\n[g] agrupar por estado\n[d] hecha/deshacer\n[F] terminar y pasar a la siguiente\n[i] en curso\n[p] pendiente\n[[/]] cambiar categoría\n[-] quitar categoría\n[c] mostrar/ocultar categorías\n[z] ajustar/recortar filas\n[{/}] anterior/siguiente %s\n[0-3] prioridad\n[+/_] subir/bajar prioridad\n[>/W] vence un día/semana después\n[*] fijar/soltar\n[N] siguiente acción\n[B] enterrar\n[M] recordatorio\n[S] fecha de inicio\n[H] mostrar/ocultar sin empezar\n[b] bloquear/desbloquear\n[space] seleccionar\n[a/A] añadir/quitar etiqueta\n[x] borrar\n[C] completar categoría\n[o] notas\n[enter] detalles\n[/] buscar\n[R] elegir una tarea pendiente al azar\n['] saltar por primera letra\n[:] línea de comandos\n[ctrl+r] recargar\n[D] abrir carpeta de datos\n[P] tareas del proyecto/globales\n[I] mostrar IDs\n[t] rotar filtro de estado\n[f] filtrar (%s)\n[backspace] filtro anterior\n[?] ocultar ayuda\n[q] salir\n[ctrl+x] salir sin guardar",
+
+	// Messages and prompts
+	"Unknown default_sort %q, using insertion order": "default_sort %q desconocido, se usa el orden de creación",
+	"Error saving config: %v":                        "Error al guardar la configuración: %v",
+	"Error reloading tasks: %v":                      "Error al recargar las tareas: %v",
+	"Reloaded %d tasks":                              "%d tareas recargadas",
+	"No unfinished tasks in %s":                      "No hay tareas sin terminar en %s",
+	"Mark %d tasks in %s as done?":                   "¿Marcar como hechas %d tareas de %s?",
+	"Error completing category: %v":                  "Error al completar la categoría: %v",
+	"Marked %d tasks in %s as done":                  "%d tareas de %s marcadas como hechas",
+	"Edit notes (Enter to save, ESC to cancel)":      "Editar notas (Enter para guardar, ESC para cancelar)",
+	"Rename task (Enter to save, ESC to cancel)":     "Renombrar tarea (Enter para guardar, ESC para cancelar)",
+	"Warning dismissed for this session":             "Aviso ocultado durante esta sesión",
+	"Restored previous filter (%s)":                  "Filtro anterior restaurado (%s)",
+	"No previous filter to restore":                  "No hay un filtro anterior para restaurar",
+	"Switched to table view":                         "Vista de tabla",
+	"Switched to list view":                          "Vista de lista",
+	"Error pinning task: %v":                         "Error al fijar la tarea: %v",
+	"Task unpinned":                                  "Tarea soltada",
+	"Task pinned":                                    "Tarea fijada",
+	"Error updating task: %v":                        "Error al actualizar la tarea: %v",
+	"Task unblocked":                                 "Tarea desbloqueada",
+	"Error updating priority: %v":                    "Error al actualizar la prioridad: %v",
+	"Priority is already %s":                         "La prioridad ya es %s",
+	"Priority: %s":                                   "Prioridad: %s",
+	"Grouped by status":                              "Agrupadas por estado",
+	"Grouping off":                                   "Sin agrupar",
+	"Task marked as pending":                         "Tarea marcada como pendiente",
+	"Task marked as done!":                           "¡Tarea marcada como hecha!",
+	"Task marked as in-progress":                     "Tarea marcada como en curso",
+	"%d tasks selected":                              "%d tareas seleccionadas",
+	"Delete this task?":                              "¿Borrar esta tarea?",
+	"Delete %d tasks?":                               "¿Borrar %d tareas?",
+	"Error deleting tasks: %v":                       "Error al borrar las tareas: %v",
+	"Task deleted":                                   "Tarea borrada",
+	"%d tasks deleted":                               "%d tareas borradas",
+	"Task creation cancelled":                        "Creación cancelada",
+	"Task creation cancelled - %v":                   "Creación cancelada - %v",
+	"Error creating task: %v":                        "Error al crear la tarea: %v",
+	"Task created: %s [%s]":                          "Tarea creada: %s [%s]",
+	"Edit cancelled":                                 "Edición cancelada",
+	"Edit cancelled - %v":                            "Edición cancelada - %v",
+	"Task updated successfully":                      "Tarea actualizada",
+	"Category unchanged":                             "Categoría sin cambios",
+	"Rename cancelled":                               "Renombrado cancelado",
+	"Rename cancelled - %v":                          "Renombrado cancelado - %v",
+	"Error renaming task: %v":                        "Error al renombrar la tarea: %v",
+	"Task renamed: %s":                               "Tarea renombrada: %s",
+	"Block cancelled":                                "Bloqueo cancelado",
+	"Task marked as blocked":                         "Tarea marcada como bloqueada",
+	"Tagging cancelled":                              "Etiquetado cancelado",
+	"Tag cannot be empty":                            "La etiqueta no puede estar vacía",
+	"Error updating tags: %v":                        "Error al actualizar las etiquetas: %v",
+	"Removed %q from %d task(s)":                     "%q quitada de %d tarea(s)",
+	"Tagged %d task(s) with %q":                      "%d tarea(s) etiquetada(s) con %q",
+	"Search cleared":                                 "Búsqueda borrada",
+	"Found %d tasks matching %q":                     "%d tareas coinciden con %q",
+	"Notes unchanged":                                "Notas sin cambios",
+	"Notes saved":                                    "Notas guardadas",
+	"Cancelled":                                      "Cancelado",
+	"%s (y/n)":                                       "%s (y/n)",
+	"Filter cancelled":                               "Filtro cancelado",
+	"Showing all tasks":                              "Mostrando todas las tareas",
+	"Showing pending tasks":                          "Mostrando tareas pendientes",
+	"Showing in-progress tasks":                      "Mostrando tareas en curso",
+	"Showing done tasks":                             "Mostrando tareas hechas",
+	"Showing tasks updated in the last 7 days":       "Mostrando tareas actualizadas en los últimos 7 días",
+	"Select category to filter by":                   "Elige la categoría por la que filtrar",
+	"Filter: %s":                                     "Filtro: %s",
+	"Showing all categories":                         "Mostrando todas las categorías",
+	"Showing tasks in category: %s":                  "Mostrando tareas de la categoría: %s",
+	"No %s tasks in view":                            "No hay tareas en estado %s a la vista",
+	"No categories to cycle through":                 "No hay categorías entre las que cambiar",
+	"Error updating category: %v":                    "Error al actualizar la categoría: %v",
+	"Category: %s":                                   "Categoría: %s",
+	"Task has no category":                           "La tarea no tiene categoría",
+	"Category cleared":                               "Categoría quitada",
+
+	"Enter task details (Tab to switch fields, Ctrl+P to pick a category, Enter to save, ESC to cancel)":                                        "Escribe los datos de la tarea (Tab para cambiar de campo, Ctrl+P para elegir categoría, Enter para guardar, ESC para cancelar)",
+	"Edit task (Tab to switch fields, Ctrl+P to pick a category, Enter to save, ESC to cancel)":                                                 "Editar tarea (Tab para cambiar de campo, Ctrl+P para elegir categoría, Enter para guardar, ESC para cancelar)",
+	"Filter: (a)ll, (p)ending, (i)n-progress, (d)one, (t)oday/actionable, (s)talled, (u)pdated this week, (c)ategory, (b)uilder, ESC to cancel": "Filtro: (a) todas, (p) pendientes, (i) en curso, (d) hechas, (t) accionables, (s) estancadas, (u) actualizadas esta semana, (c) categoría, (b) constructor, ESC para cancelar",
+	"Blocked by? (optional, Enter to save, ESC to cancel)":                                                                                      "¿Bloqueada por? (opcional, Enter para guardar, ESC para cancelar)",
+	"Tag to add to %d task(s)? (Enter to apply, ESC to cancel)":                                                                                 "¿Etiqueta a añadir a %d tarea(s)? (Enter para aplicar, ESC para cancelar)",
+	"Tag to remove from %d task(s)? (Enter to apply, ESC to cancel)":                                                                            "¿Etiqueta a quitar de %d tarea(s)? (Enter para aplicar, ESC para cancelar)",
+	"Similar task exists: %s [%s] - press Enter again to create anyway":                                                                         "Ya existe una tarea parecida: %s [%s] - pulsa Enter otra vez para crearla igualmente",
+	"↑/↓ choose, Enter to select, ESC to go back":                                                                                               "↑/↓ elegir, Enter para seleccionar, ESC para volver",
+	"Type the new category (Enter to save, ESC to cancel)":                                                                                      "Escribe la nueva categoría (Enter para guardar, ESC para cancelar)",
+	"Category: %s (Enter to save, ESC to cancel)":                                                                                               "Categoría: %s (Enter para guardar, ESC para cancelar)",
+	"Search all fields (Tab to toggle, Enter to apply, ESC to clear)":                                                                           "Buscar en todos los campos (Tab para cambiar, Enter para aplicar, ESC para borrar)",
+	"Search description only (Tab to toggle, Enter to apply, ESC to clear)":                                                                     "Buscar solo en la descripción (Tab para cambiar, Enter para aplicar, ESC para borrar)",
+	"Showing actionable tasks (not done, not blocked)":                                                                                          "Mostrando tareas accionables (ni hechas ni bloqueadas)",
+	"Showing tasks in progress for over %d days without updates":                                                                                "Mostrando tareas en curso sin cambios desde hace más de %d días",
+	"↑/↓ choose field, ←/→ change value, Enter to apply, ESC to cancel":                                                                         "↑/↓ elegir campo, ←/→ cambiar valor, Enter para aplicar, ESC para cancelar",
+}
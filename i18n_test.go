@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		configured, lcAll, lang string
+		want                    string
+	}{
+		{"", "", "", "en"},
+		{"", "", "es_AR.UTF-8", "es"},
+		{"", "es_ES.UTF-8", "en_US.UTF-8", "es"},
+		{"en", "", "es_AR.UTF-8", "en"},
+		{"es", "", "", "es"},
+		{"", "", "fr_FR.UTF-8", "en"},
+		{"", "", "C", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LANG", tt.lang)
+		if got := detectLanguage(tt.configured); got != tt.want {
+			t.Errorf("detectLanguage(%q) with LC_ALL=%q LANG=%q = %q, want %q", tt.configured, tt.lcAll, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	if got := translate("es", "Task deleted"); got != "Tarea borrada" {
+		t.Errorf("Expected Spanish text, got %q", got)
+	}
+	if got := translate("es", "%d tasks deleted", 3); got != "3 tareas borradas" {
+		t.Errorf("Expected formatted Spanish text, got %q", got)
+	}
+	if got := translate("en", "%d tasks deleted", 3); got != "3 tasks deleted" {
+		t.Errorf("Expected English text, got %q", got)
+	}
+	if got := translate("es", "Not in the catalog"); got != "Not in the catalog" {
+		t.Errorf("Expected the key for a missing entry, got %q", got)
+	}
+}
+
+// Every literal passed to m.t in the UI has a Spanish translation
+func TestSpanishCatalogCoversUI(t *testing.T) {
+	src, err := os.ReadFile("ui.go")
+	if err != nil {
+		t.Fatalf("Failed to read ui.go: %v", err)
+	}
+
+	keys := regexp.MustCompile(`m\.t\(("(?:[^"\\]|\\.)*")\s*[,)]`).FindAllStringSubmatch(string(src), -1)
+	if len(keys) == 0 {
+		t.Fatal("Expected translated strings in ui.go")
+	}
+	for _, match := range keys {
+		key, err := strconv.Unquote(match[1])
+		if err != nil {
+			t.Fatalf("Failed to unquote %s: %v", match[1], err)
+		}
+		if _, ok := spanish[key]; !ok {
+			t.Errorf("Missing Spanish translation for %q", key)
+		}
+	}
+
+	// Values translated through variables
+	for _, key := range []string{
+		string(StatusPending), string(StatusInProgress), string(StatusDone),
+		PriorityLow.String(), PriorityMedium.String(), PriorityHigh.String(),
+		DueOverdue.String(), DueToday.String(), DueThisWeek.String(),
+		statusGroupTitle(StatusPending), statusGroupTitle(StatusInProgress), statusGroupTitle(StatusDone),
+		fieldDescription, fieldNotes, fieldCategory, pickerNew,
+		defaultTitle, defaultEmptyMessage,
+	} {
+		if _, ok := spanish[key]; !ok {
+			t.Errorf("Missing Spanish translation for %q", key)
+		}
+	}
+}
+
+func TestModel_SpanishView(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	m.lang = "es"
+	if err := m.store.Add("Comprar pan", "casa"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	view := m.View()
+	if !contains(view, "Descripción") || !contains(view, "[n] nueva tarea") {
+		t.Errorf("Expected the Spanish table header and help, got:\n%s", view)
+	}
+
+	// Status names in filter messages are translated too
+	m.viewMode = ModeFilter
+	updatedModel, _ := m.updateFilterMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = updatedModel.(model)
+	if m.message != "Mostrando tareas en curso" {
+		t.Errorf("Expected a Spanish filter message, got %q", m.message)
+	}
+	if !contains(m.View(), "en curso") {
+		t.Errorf("Expected the translated status in the header, got:\n%s", m.View())
+	}
+}
+
+func TestModel_SpanishEmptyViewWithDefaultConfig(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	m.lang = "es"
+	m.showWelcome = false
+	view := m.View()
+	if !contains(view, "Todavía no hay tareas") {
+		t.Errorf("Expected the default empty message in Spanish, got:\n%s", view)
+	}
+	if contains(view, defaultEmptyMessage) {
+		t.Errorf("Expected no English empty message, got:\n%s", view)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// importDedupe decides which incoming tasks an import skips as duplicates
+type importDedupe int
+
+const (
+	dedupeNone     importDedupe = iota
+	dedupeAll                   // skip descriptions matching any task
+	dedupeCategory              // skip descriptions matching a task in the same category
+)
+
+// key is what two tasks share when d considers them duplicates
+func (d importDedupe) key(description string, category TaskCategory) string {
+	key := normalizeDescription(description)
+	if d == dedupeCategory {
+		key = string(category) + "\x00" + key
+	}
+	return key
+}
+
+// filter drops the incoming tasks whose normalized description matches an
+// existing task or an earlier incoming one, and returns the rest with the
+// number skipped
+func (d importDedupe) filter(existing, incoming []Task) ([]Task, int) {
+	if d == dedupeNone {
+		return incoming, 0
+	}
+
+	seen := make(map[string]bool, len(existing)+len(incoming))
+	for _, task := range existing {
+		seen[d.key(task.Description, task.Category)] = true
+	}
+	var kept []Task
+	for _, task := range incoming {
+		key := d.key(task.Description, task.Category)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, task)
+	}
+	return kept, len(incoming) - len(kept)
+}
+
+// ImportLines creates a task for each non-blank line read from r, all in
+// the given category, with a single save. It returns the number of tasks
+// created.
+func (s *TaskStore) ImportLines(r io.Reader, category TaskCategory) (int, error) {
+	count, _, err := s.importLines(r, category, dedupeNone)
+	return count, err
+}
+
+// importLines is ImportLines with duplicates skipped according to dedupe.
+// It also returns the number of lines skipped.
+func (s *TaskStore) importLines(r io.Reader, category TaskCategory, dedupe importDedupe) (int, int, error) {
+	var tasks []Task
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			tasks = append(tasks, Task{Description: line, Category: category})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(tasks) == 0 {
+		return 0, 0, nil
+	}
+
+	count, skipped := 0, 0
+	err := s.Batch(func(b *TaskBatch) error {
+		var kept []Task
+		kept, skipped = dedupe.filter(b.store.tasks, tasks)
+		for _, task := range kept {
+			b.Add(task.Description, task.Category)
+		}
+		count = len(kept)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, skipped, nil
+}
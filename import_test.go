@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaskStore_ImportLines(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	input := "Buy milk\n\n  Call mom  \nPay rent\n"
+	count, err := store.ImportLines(strings.NewReader(input), "home")
+	if err != nil {
+		t.Fatalf("Failed to import lines: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("Expected 3 imported tasks, got %d", count)
+	}
+
+	tasks := store.GetAll()
+	if tasks[1].Description != "Call mom" {
+		t.Errorf("Expected trimmed description 'Call mom', got '%s'", tasks[1].Description)
+	}
+	for _, task := range tasks {
+		if task.Category != "home" {
+			t.Errorf("Expected category 'home', got '%s'", task.Category)
+		}
+	}
+}
+
+func TestTaskStore_ImportLinesDedupe(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if err := store.Add("Buy milk", "home"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := store.Add("Pay rent", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	// "buy  MILK" matches after normalizing, "Call mom" repeats within
+	// the input, and "Pay rent" only exists in another category
+	input := "buy  MILK\nCall mom\nCall mom\nPay rent\n"
+	count, skipped, err := store.importLines(strings.NewReader(input), "home", dedupeCategory)
+	if err != nil {
+		t.Fatalf("Failed to import lines: %v", err)
+	}
+	if count != 2 || skipped != 2 {
+		t.Fatalf("Expected 2 imported and 2 skipped, got %d and %d", count, skipped)
+	}
+	tasks := store.GetAll()
+	if len(tasks) != 4 || tasks[2].Description != "Call mom" || tasks[3].Description != "Pay rent" {
+		t.Errorf("Expected Call mom and Pay rent added once each, got %+v", tasks)
+	}
+
+	// Across all categories the work task counts as a duplicate too
+	count, skipped, err = store.importLines(strings.NewReader("Pay rent\nWater plants\n"), "errands", dedupeAll)
+	if err != nil {
+		t.Fatalf("Failed to import lines: %v", err)
+	}
+	if count != 1 || skipped != 1 {
+		t.Errorf("Expected 1 imported and 1 skipped, got %d and %d", count, skipped)
+	}
+
+	// Without dedupe everything is imported
+	count, skipped, err = store.importLines(strings.NewReader("Buy milk\n"), "home", dedupeNone)
+	if err != nil || count != 1 || skipped != 0 {
+		t.Errorf("Expected the duplicate imported without dedupe, got %d and %d (%v)", count, skipped, err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// linkOpenedMsg reports that the OS opener for a link has exited
+type linkOpenedMsg struct {
+	err error
+}
+
+// openerCommand returns the command that opens link with the default
+// application on goos
+func openerCommand(goos, link string) *exec.Cmd {
+	switch goos {
+	case "darwin":
+		return exec.Command("open", link)
+	case "windows":
+		// The empty argument is start's window title, so a quoted link
+		// isn't mistaken for one
+		return exec.Command("cmd", "/c", "start", "", link)
+	default:
+		return exec.Command("xdg-open", link)
+	}
+}
+
+// openLink runs the OS opener for link (a URL, file or directory), handing
+// it the terminal until it exits
+func openLink(link string) tea.Cmd {
+	return tea.ExecProcess(openerCommand(runtime.GOOS, link), func(err error) tea.Msg {
+		return linkOpenedMsg{err: err}
+	})
+}
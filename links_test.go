@@ -0,0 +1,23 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOpenerCommand(t *testing.T) {
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{"darwin", []string{"open", "https://example.com"}},
+		{"windows", []string{"cmd", "/c", "start", "", "https://example.com"}},
+		{"linux", []string{"xdg-open", "https://example.com"}},
+		{"freebsd", []string{"xdg-open", "https://example.com"}},
+	}
+	for _, tt := range tests {
+		if got := openerCommand(tt.goos, "https://example.com").Args; !slices.Equal(got, tt.want) {
+			t.Errorf("openerCommand(%q) args = %q, want %q", tt.goos, got, tt.want)
+		}
+	}
+}
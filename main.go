@@ -3,20 +3,73 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// Build information, set at build time with
+// -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=..."
+var (
+	version   = "dev"
+	commit    = "none"
+	buildDate = "unknown"
+)
+
+// versionString formats the build information for --version
+func versionString() string {
+	return fmt.Sprintf("patodo %s (commit %s, built %s)", version, commit, buildDate)
+}
+
 func main() {
-	store, err := NewTaskStore()
+	if len(os.Args) > 1 && (os.Args[1] == "--version" || os.Args[1] == "-version") {
+		fmt.Println(versionString())
+		os.Exit(exitOK)
+	}
+
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	store, err := NewTaskStore(cfg)
 	if err != nil {
 		fmt.Printf("Error initializing task store: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 
-	p := tea.NewProgram(initialModel(store), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	if cfg.AutoArchiveDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -cfg.AutoArchiveDays)
+		count, err := store.ArchiveDoneBefore(cutoff)
+		// Not worth refusing to start over; the next start tries again
+		if err != nil {
+			fmt.Printf("Error auto-archiving tasks: %v\n", err)
+		} else if count > 0 {
+			fmt.Printf("Auto-archived %d tasks done more than %d days ago\n", count, cfg.AutoArchiveDays)
+		}
+	}
+
+	// The TUI saves in the background; flush whatever is left on exit
+	// unless the user chose to discard it
+	store.DeferSaves()
+	m := initialModel(store, cfg)
+	m.output = os.Stdout
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(m.output))
+	final, err := p.Run()
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitError)
+	}
+	// P may have switched stores, so flush the one the TUI ended with
+	if m, ok := final.(model); ok && !m.discarded {
+		if err := m.store.Flush(); err != nil {
+			fmt.Printf("Error saving tasks: %v\n", err)
+			os.Exit(exitError)
+		}
 	}
 }
@@ -6,6 +6,14 @@ import (
 	"testing"
 )
 
+// TestMain pins the locale so the UI tests see English strings regardless
+// of the environment they run in
+func TestMain(m *testing.M) {
+	_ = os.Unsetenv("LC_ALL")
+	_ = os.Setenv("LANG", "C")
+	os.Exit(m.Run())
+}
+
 func TestMain_Integration(t *testing.T) {
 	// This is an integration test that verifies the main components work together
 	// We can't easily test the actual main() function since it runs the TUI,
@@ -37,7 +45,7 @@ func TestMain_Integration(t *testing.T) {
 	}
 
 	// Create initial model
-	m := initialModel(store)
+	m := initialModel(store, DefaultConfig())
 
 	// Verify model is properly initialized
 	if m.store == nil {
@@ -72,7 +80,7 @@ func TestNewTaskStore_Integration(t *testing.T) {
 	// Test that NewTaskStore creates the directory structure
 	// This will use the actual config directory, so we need to be careful
 
-	store, err := NewTaskStore()
+	store, err := NewTaskStore(DefaultConfig())
 	if err != nil {
 		t.Fatalf("NewTaskStore failed: %v", err)
 	}
@@ -119,7 +127,7 @@ func TestEndToEnd_TaskLifecycle(t *testing.T) {
 	}
 
 	// Create model
-	m := initialModel(store)
+	m := initialModel(store, DefaultConfig())
 
 	// 1. Start in list mode with no tasks
 	if len(m.tasks) != 0 {
@@ -218,3 +226,17 @@ func TestEndToEnd_TaskLifecycle(t *testing.T) {
 		t.Error("Loaded task description doesn't match")
 	}
 }
+
+func TestVersionString(t *testing.T) {
+	prevVersion, prevCommit, prevDate := version, commit, buildDate
+	defer func() { version, commit, buildDate = prevVersion, prevCommit, prevDate }()
+
+	if got := versionString(); got != "patodo dev (commit none, built unknown)" {
+		t.Errorf("Unexpected default version string: %q", got)
+	}
+
+	version, commit, buildDate = "1.2.0", "abc1234", "2026-01-02"
+	if got := versionString(); got != "patodo 1.2.0 (commit abc1234, built 2026-01-02)" {
+		t.Errorf("Unexpected version string: %q", got)
+	}
+}
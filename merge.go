@@ -0,0 +1,43 @@
+package main
+
+// mergeStats counts what merging a second task list changed
+type mergeStats struct {
+	Added     int // tasks only in the second list
+	Updated   int // conflicts won by the second list's newer version
+	Conflicts int // tasks present in both lists with different content
+}
+
+// mergeTasks returns the union of two task lists, deduplicated by ID. When
+// both lists hold different versions of a task, the one with the newer
+// UpdatedAt wins (the first list's on a tie). Order follows a, then the
+// tasks only in b.
+func mergeTasks(a, b []Task) ([]Task, mergeStats) {
+	var stats mergeStats
+
+	merged := make([]Task, len(a))
+	copy(merged, a)
+	index := make(map[string]int, len(a))
+	for i, task := range merged {
+		index[task.ID] = i
+	}
+
+	for _, task := range b {
+		i, ok := index[task.ID]
+		if !ok {
+			index[task.ID] = len(merged)
+			merged = append(merged, task)
+			stats.Added++
+			continue
+		}
+		if sameTask(merged[i], task) {
+			continue
+		}
+
+		stats.Conflicts++
+		if task.UpdatedAt.After(merged[i].UpdatedAt) {
+			merged[i] = task
+			stats.Updated++
+		}
+	}
+	return merged, stats
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeTasks(t *testing.T) {
+	base := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	task := func(id, desc string, updated time.Time) Task {
+		return Task{ID: id, Description: desc, Status: StatusPending, CreatedAt: base, UpdatedAt: updated}
+	}
+
+	a := []Task{
+		task("1", "Same on both", base),
+		task("2", "Newer in a", base.Add(2*time.Hour)),
+		task("3", "Older in a", base),
+		task("4", "Only in a", base),
+	}
+	b := []Task{
+		task("1", "Same on both", base),
+		task("2", "Older in b", base.Add(time.Hour)),
+		task("3", "Newer in b", base.Add(time.Hour)),
+		task("5", "Only in b", base),
+	}
+
+	merged, stats := mergeTasks(a, b)
+
+	want := []string{"Same on both", "Newer in a", "Newer in b", "Only in a", "Only in b"}
+	if len(merged) != len(want) {
+		t.Fatalf("Expected %d merged tasks, got %d", len(want), len(merged))
+	}
+	for i, desc := range want {
+		if merged[i].Description != desc {
+			t.Errorf("Expected task %d to be '%s', got '%s'", i, desc, merged[i].Description)
+		}
+	}
+
+	if stats != (mergeStats{Added: 1, Updated: 1, Conflicts: 2}) {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// The inputs are left untouched
+	if a[2].Description != "Older in a" {
+		t.Error("Expected mergeTasks not to modify its input")
+	}
+}
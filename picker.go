@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pickerNew is the picker option that asks for a value not in the list
+const pickerNew = "new..."
+
+// pickerResult tells the caller what a key did to a picker
+type pickerResult int
+
+const (
+	pickerPending   pickerResult = iota // still choosing
+	pickerChosen                        // Selected holds the choice
+	pickerCancelled                     // closed without a choice
+)
+
+// picker lets the user choose one of a list of strings with the arrow keys
+type picker struct {
+	title   string
+	options []string
+	cursor  int
+}
+
+// newPicker returns a picker over options with the cursor on current, or
+// on the first option if current isn't one of them
+func newPicker(title string, options []string, current string) picker {
+	p := picker{title: title, options: options}
+	for i, option := range options {
+		if option == current {
+			p.cursor = i
+			break
+		}
+	}
+	return p
+}
+
+// Update moves the cursor or finishes the choice
+func (p picker) Update(msg tea.KeyMsg) (picker, pickerResult) {
+	switch msg.String() {
+	case "esc":
+		return p, pickerCancelled
+	case "enter":
+		if len(p.options) == 0 {
+			return p, pickerCancelled
+		}
+		return p, pickerChosen
+	case "up", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "j":
+		if p.cursor < len(p.options)-1 {
+			p.cursor++
+		}
+	}
+	return p, pickerPending
+}
+
+// Selected returns the option under the cursor
+func (p picker) Selected() string {
+	if len(p.options) == 0 {
+		return ""
+	}
+	return p.options[p.cursor]
+}
+
+// View renders the title and options, marking the one under the cursor
+func (p picker) View() string {
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorTitle))
+
+	var s strings.Builder
+	s.WriteString(p.title + "\n")
+	for i, option := range p.options {
+		if i == p.cursor {
+			s.WriteString(selectedStyle.Render("> "+option) + "\n")
+		} else {
+			s.WriteString("  " + option + "\n")
+		}
+	}
+	return s.String()
+}
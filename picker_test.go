@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPicker_Navigation(t *testing.T) {
+	p := newPicker("Category:", []string{"home", "work", pickerNew}, "work")
+	if p.Selected() != "work" {
+		t.Fatalf("Expected the cursor on the current value, got %q", p.Selected())
+	}
+
+	var result pickerResult
+	for _, key := range []tea.KeyType{tea.KeyDown, tea.KeyDown, tea.KeyDown} {
+		p, result = p.Update(tea.KeyMsg{Type: key})
+		if result != pickerPending {
+			t.Fatalf("Expected the picker to stay open, got %d", result)
+		}
+	}
+	if p.Selected() != pickerNew {
+		t.Errorf("Expected the cursor to stop at the last option, got %q", p.Selected())
+	}
+
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	if p, result = p.Update(tea.KeyMsg{Type: tea.KeyEnter}); result != pickerChosen || p.Selected() != "work" {
+		t.Errorf("Expected 'work' to be chosen, got %q (%d)", p.Selected(), result)
+	}
+
+	if _, result = p.Update(tea.KeyMsg{Type: tea.KeyEsc}); result != pickerCancelled {
+		t.Errorf("Expected ESC to cancel, got %d", result)
+	}
+}
+
+func TestPicker_Empty(t *testing.T) {
+	p := newPicker("Category:", nil, "")
+	if p.Selected() != "" {
+		t.Errorf("Expected no selection, got %q", p.Selected())
+	}
+	if _, result := p.Update(tea.KeyMsg{Type: tea.KeyEnter}); result != pickerCancelled {
+		t.Errorf("Expected Enter on an empty picker to cancel, got %d", result)
+	}
+}
+
+func TestPicker_View(t *testing.T) {
+	p := newPicker("Category:", []string{"home", "work"}, "home")
+	view := p.View()
+	if !strings.Contains(view, "Category:") || !strings.Contains(view, "> home") || !strings.Contains(view, "  work") {
+		t.Errorf("Unexpected picker view:\n%s", view)
+	}
+}
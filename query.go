@@ -0,0 +1,35 @@
+package main
+
+import "strings"
+
+// Fields a search query can match
+const (
+	fieldDescription = "description"
+	fieldNotes       = "notes"
+	fieldCategory    = "category"
+)
+
+// matchQuery reports whether task matches query, case-insensitively, and
+// which field matched first. Only the description is searched unless
+// allFields is set, in which case notes and category are searched too. An
+// empty query matches every task.
+func matchQuery(task Task, query string, allFields bool) (string, bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return "", true
+	}
+
+	if strings.Contains(strings.ToLower(task.Description), query) {
+		return fieldDescription, true
+	}
+	if !allFields {
+		return "", false
+	}
+	if strings.Contains(strings.ToLower(task.Notes), query) {
+		return fieldNotes, true
+	}
+	if strings.Contains(strings.ToLower(string(task.Category)), query) {
+		return fieldCategory, true
+	}
+	return "", false
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestMatchQuery(t *testing.T) {
+	task := Task{Description: "Write report", Notes: "Ask Dana for the Q3 numbers", Category: "finance"}
+
+	tests := []struct {
+		query     string
+		allFields bool
+		wantField string
+		wantOK    bool
+	}{
+		{"", false, "", true},
+		{"REPORT", false, fieldDescription, true},
+		{"dana", false, "", false},
+		{"dana", true, fieldNotes, true},
+		{"finance", false, "", false},
+		{"finance", true, fieldCategory, true},
+		{"budget", true, "", false},
+	}
+
+	for _, tt := range tests {
+		field, ok := matchQuery(task, tt.query, tt.allFields)
+		if field != tt.wantField || ok != tt.wantOK {
+			t.Errorf("matchQuery(%q, %v) = (%q, %v), want (%q, %v)", tt.query, tt.allFields, field, ok, tt.wantField, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errTaskNotFound is returned when an operation targets an unknown task ID
+var errTaskNotFound = errors.New("task not found")
+
+// errBatchRejected rolls back an atomic batch with a failed operation
+var errBatchRejected = errors.New("batch rejected")
+
+// taskServer exposes a TaskStore over HTTP
+type taskServer struct {
+	store *TaskStore
+}
+
+// taskRequest is the JSON body accepted when creating or updating a task.
+// Fields left out are left unchanged on update.
+type taskRequest struct {
+	Description *string       `json:"description"`
+	Status      *TaskStatus   `json:"status"`
+	Category    *TaskCategory `json:"category"`
+	DueDate     *string       `json:"due_date"` // YYYY-MM-DD or RFC 3339; "" clears it
+}
+
+// validate checks the fields present in the request
+func (req taskRequest) validate() error {
+	if req.Description != nil && strings.TrimSpace(*req.Description) == "" {
+		return errors.New("description is required")
+	}
+	if req.Status != nil && !isValidStatus(*req.Status) {
+		return errors.New("invalid status: " + string(*req.Status))
+	}
+	if req.DueDate != nil {
+		if _, err := parseDueDate(*req.DueDate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// empty reports whether the request sets no fields
+func (req taskRequest) empty() bool {
+	return req == taskRequest{}
+}
+
+// parseDueDate parses a due date given as a local date or an RFC 3339
+// time. An empty string means no due date.
+func parseDueDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if due, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return &due, nil
+	}
+	due, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, errors.New("invalid due_date: " + s)
+	}
+	return &due, nil
+}
+
+// batchOperation is a single item of a POST /tasks/batch request
+type batchOperation struct {
+	Op string `json:"op"` // create, update, or delete
+	ID string `json:"id"`
+	taskRequest
+}
+
+// batchResult reports the outcome of one batch operation
+type batchResult struct {
+	Index int    `json:"index"`
+	OK    bool   `json:"ok"`
+	Task  *Task  `json:"task,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// batchResponse is the JSON body returned by POST /tasks/batch
+type batchResponse struct {
+	Applied bool          `json:"applied"`
+	Results []batchResult `json:"results"`
+}
+
+// errorResponse is the JSON body returned on failure
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// newTaskServer creates a server backed by the given store
+func newTaskServer(store *TaskStore) *taskServer {
+	return &taskServer{store: store}
+}
+
+// serve listens on addr and serves the task API
+func serve(addr string, store *TaskStore) error {
+	return http.ListenAndServe(addr, newTaskServer(store).routes())
+}
+
+// routes returns the handler for all API endpoints
+func (srv *taskServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", srv.handleList)
+	mux.HandleFunc("POST /tasks", srv.handleCreate)
+	mux.HandleFunc("POST /tasks/batch", srv.handleBatch)
+	mux.HandleFunc("PATCH /tasks/{id}", srv.handleUpdate)
+	mux.HandleFunc("DELETE /tasks/{id}", srv.handleDelete)
+	return mux
+}
+
+func (srv *taskServer) handleList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, srv.store.GetAll())
+}
+
+func (srv *taskServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req taskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	var description string
+	var category TaskCategory
+	if req.Description != nil {
+		description = *req.Description
+	}
+	if req.Category != nil {
+		category = *req.Category
+	}
+	if err := validateTask(description, category, true); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var task Task
+	err := srv.store.Batch(func(b *TaskBatch) error {
+		task = createTask(b, req)
+		return nil
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, task)
+}
+
+// handleUpdate applies a partial update: only the fields present in the
+// body change, and the updated task is returned
+func (srv *taskServer) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req taskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Nothing to change, so nothing is written and UpdatedAt stays put
+	if req.empty() {
+		task, ok := srv.store.Get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, errTaskNotFound.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, task)
+		return
+	}
+
+	var task Task
+	err := srv.store.Batch(func(b *TaskBatch) error {
+		var err error
+		task, err = updateTask(b, r.PathValue("id"), req)
+		return err
+	})
+	if errors.Is(err, errTaskNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (srv *taskServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	err := srv.store.Batch(func(b *TaskBatch) error {
+		if !b.Delete(r.PathValue("id")) {
+			return errTaskNotFound
+		}
+		return nil
+	})
+	if errors.Is(err, errTaskNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleBatch applies several operations with a single save. By default the
+// batch is atomic: if any operation fails, none are applied. With
+// ?atomic=false, valid operations are applied and failures are skipped.
+func (srv *taskServer) handleBatch(w http.ResponseWriter, r *http.Request) {
+	atomic := true
+	if v := r.URL.Query().Get("atomic"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid atomic flag")
+			return
+		}
+		atomic = parsed
+	}
+
+	var ops []batchOperation
+	if err := json.NewDecoder(r.Body).Decode(&ops); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	results := make([]batchResult, len(ops))
+	err := srv.store.Batch(func(b *TaskBatch) error {
+		failed := false
+		for i, op := range ops {
+			results[i] = applyOperation(b, i, op)
+			if !results[i].OK {
+				failed = true
+			}
+		}
+		if failed && atomic {
+			return errBatchRejected
+		}
+		return nil
+	})
+
+	if errors.Is(err, errBatchRejected) {
+		for i := range results {
+			if results[i].OK {
+				results[i] = batchResult{Index: i, Error: "not applied: batch rolled back"}
+			}
+		}
+		writeJSON(w, http.StatusBadRequest, batchResponse{Applied: false, Results: results})
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, batchResponse{Applied: true, Results: results})
+}
+
+// applyOperation validates and applies a single batch operation
+func applyOperation(b *TaskBatch, index int, op batchOperation) batchResult {
+	result := batchResult{Index: index}
+	if err := op.validate(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	var task Task
+	switch op.Op {
+	case "create":
+		task = createTask(b, op.taskRequest)
+	case "update":
+		var err error
+		if task, err = updateTask(b, op.ID, op.taskRequest); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	case "delete":
+		var ok bool
+		if task, ok = b.Get(op.ID); !ok || !b.Delete(op.ID) {
+			result.Error = errTaskNotFound.Error()
+			return result
+		}
+	}
+
+	result.OK = true
+	result.Task = &task
+	return result
+}
+
+// validate checks that an operation is structurally valid
+func (op batchOperation) validate() error {
+	switch op.Op {
+	case "create":
+		// The same rules as POST /tasks
+		var description string
+		var category TaskCategory
+		if op.Description != nil {
+			description = *op.Description
+		}
+		if op.Category != nil {
+			category = *op.Category
+		}
+		if err := validateTask(description, category, true); err != nil {
+			return err
+		}
+	case "update", "delete":
+		if op.ID == "" {
+			return errors.New("id is required")
+		}
+	default:
+		return errors.New("unknown op: " + op.Op)
+	}
+
+	return op.taskRequest.validate()
+}
+
+// createTask adds a task described by req
+func createTask(b *TaskBatch, req taskRequest) Task {
+	var category TaskCategory
+	if req.Category != nil {
+		category = *req.Category
+	}
+
+	task := b.Add(*req.Description, category)
+	if req.Status != nil {
+		b.UpdateStatus(task.ID, *req.Status)
+	}
+	if req.DueDate != nil {
+		due, _ := parseDueDate(*req.DueDate) // checked by validate
+		b.SetDueDate(task.ID, due)
+	}
+	task, _ = b.Get(task.ID)
+	return task
+}
+
+// updateTask applies the fields present in req to an existing task
+func updateTask(b *TaskBatch, id string, req taskRequest) (Task, error) {
+	task, ok := b.Get(id)
+	if !ok {
+		return Task{}, errTaskNotFound
+	}
+
+	if req.Description != nil || req.Category != nil {
+		if req.Description != nil {
+			task.Description = *req.Description
+		}
+		if req.Category != nil {
+			task.Category = *req.Category
+		}
+		b.Update(id, task.Description, task.Category)
+	}
+	if req.Status != nil {
+		b.UpdateStatus(id, *req.Status)
+	}
+	if req.DueDate != nil {
+		due, _ := parseDueDate(*req.DueDate) // checked by validate
+		b.SetDueDate(id, due)
+	}
+
+	task, _ = b.Get(id)
+	return task, nil
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
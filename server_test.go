@@ -0,0 +1,401 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) (*taskServer, http.Handler) {
+	t.Helper()
+
+	srv := newTaskServer(setupTestStore(t))
+	return srv, srv.routes()
+}
+
+func TestServer_ListTasks(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	// Empty store returns an empty array, not null
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("Expected empty array, got %s", rec.Body.String())
+	}
+
+	if err := srv.store.Add("Task 1", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tasks", nil))
+	var tasks []Task
+	if err := json.NewDecoder(rec.Body).Decode(&tasks); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Description != "Task 1" {
+		t.Errorf("Expected one task 'Task 1', got %+v", tasks)
+	}
+}
+
+func TestServer_CreateTask(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	body := strings.NewReader(`{"description": "Buy milk", "category": "shopping"}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks", body))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var task Task
+	if err := json.NewDecoder(rec.Body).Decode(&task); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if task.ID == "" {
+		t.Error("Expected created task to have an ID")
+	}
+	if task.Category != "shopping" {
+		t.Errorf("Expected category 'shopping', got '%s'", task.Category)
+	}
+	if len(srv.store.GetAll()) != 1 {
+		t.Errorf("Expected 1 task in store, got %d", len(srv.store.GetAll()))
+	}
+
+	// Malformed body
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader("{")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for malformed body, got %d", rec.Code)
+	}
+}
+
+func TestServer_CreateTaskValidation(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"category": "work"}`, "description is required"},
+		{`{"description": "   ", "category": "work"}`, "description is required"},
+		{`{"description": "Buy milk"}`, "category is required"},
+		{`{"description": "Buy milk", "category": ""}`, "category is required"},
+		{`{"description": "Buy milk", "category": "home", "status": "sleeping"}`, "invalid status: sleeping"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(tt.body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", tt.body, rec.Code)
+			continue
+		}
+		var resp errorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode error response: %v", err)
+		}
+		if resp.Error != tt.want {
+			t.Errorf("%s: expected error %q, got %q", tt.body, tt.want, resp.Error)
+		}
+	}
+
+	if len(srv.store.GetAll()) != 0 {
+		t.Errorf("Expected no tasks to be created, got %d", len(srv.store.GetAll()))
+	}
+}
+
+func TestServer_UpdateTask(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	if err := srv.store.Add("Task 1", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := srv.store.GetAll()[0].ID
+
+	body := strings.NewReader(`{"status": "done"}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/tasks/"+id, body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	task := srv.store.GetAll()[0]
+	if task.Status != StatusDone {
+		t.Errorf("Expected status 'done', got '%s'", task.Status)
+	}
+	if task.Description != "Task 1" || task.Category != "work" {
+		t.Errorf("Expected other fields preserved, got %+v", task)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/tasks/missing", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown task, got %d", rec.Code)
+	}
+}
+
+func TestServer_DeleteTask(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	if err := srv.store.Add("Task 1", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := srv.store.GetAll()[0].ID
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/tasks/"+id, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+	if len(srv.store.GetAll()) != 0 {
+		t.Error("Expected task to be deleted")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/tasks/"+id, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for already-deleted task, got %d", rec.Code)
+	}
+}
+
+func decodeBatch(t *testing.T, rec *httptest.ResponseRecorder) batchResponse {
+	t.Helper()
+
+	var resp batchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode batch response: %v", err)
+	}
+	return resp
+}
+
+func TestServer_Batch_AllValid(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	if err := srv.store.Add("Existing", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := srv.store.GetAll()[0].ID
+
+	body := strings.NewReader(`[
+		{"op": "create", "description": "New task", "category": "home"},
+		{"op": "update", "id": "` + id + `", "status": "done"}
+	]`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks/batch", body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	resp := decodeBatch(t, rec)
+	if !resp.Applied || len(resp.Results) != 2 {
+		t.Fatalf("Expected 2 applied results, got %+v", resp)
+	}
+	for _, result := range resp.Results {
+		if !result.OK {
+			t.Errorf("Expected result %d to succeed, got %q", result.Index, result.Error)
+		}
+	}
+
+	tasks := srv.store.GetAll()
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].Status != StatusDone {
+		t.Errorf("Expected existing task done, got '%s'", tasks[0].Status)
+	}
+}
+
+func TestServer_Batch_AtomicRollback(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	body := strings.NewReader(`[
+		{"op": "create", "description": "Valid", "category": "work"},
+		{"op": "create", "description": ""},
+		{"op": "explode"}
+	]`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks/batch", body))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	resp := decodeBatch(t, rec)
+	if resp.Applied {
+		t.Error("Expected atomic batch not to be applied")
+	}
+	for _, result := range resp.Results {
+		if result.OK || result.Error == "" {
+			t.Errorf("Expected result %d to report an error, got %+v", result.Index, result)
+		}
+	}
+	if len(srv.store.GetAll()) != 0 {
+		t.Errorf("Expected no tasks after rollback, got %d", len(srv.store.GetAll()))
+	}
+}
+
+func TestServer_Batch_PartialFailure(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	body := strings.NewReader(`[
+		{"op": "create", "description": "Valid", "category": "work"},
+		{"op": "delete", "id": "missing"},
+		{"op": "create", "description": "Bad status", "status": "sleeping"}
+	]`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks/batch?atomic=false", body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	resp := decodeBatch(t, rec)
+	if !resp.Applied {
+		t.Error("Expected non-atomic batch to be applied")
+	}
+	if !resp.Results[0].OK {
+		t.Errorf("Expected first operation to succeed, got %q", resp.Results[0].Error)
+	}
+	if resp.Results[1].OK || resp.Results[2].OK {
+		t.Error("Expected invalid operations to fail")
+	}
+
+	tasks := srv.store.GetAll()
+	if len(tasks) != 1 || tasks[0].Description != "Valid" {
+		t.Errorf("Expected only the valid task to be created, got %+v", tasks)
+	}
+}
+
+func TestServer_PatchSingleField(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	if err := srv.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := srv.store.GetAll()[0].ID
+	if err := srv.store.UpdateStatus(id, StatusInProgress); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/tasks/"+id, strings.NewReader(`{"due_date": "2026-06-01"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var task Task
+	if err := json.NewDecoder(rec.Body).Decode(&task); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if task.DueDate == nil || task.DueDate.Format(time.DateOnly) != "2026-06-01" {
+		t.Errorf("Expected the due date in the response, got %v", task.DueDate)
+	}
+	if task.Description != "Write report" || task.Category != "work" || task.Status != StatusInProgress {
+		t.Errorf("Expected other fields preserved, got %+v", task)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/tasks/"+id, strings.NewReader(`{"category": "home"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	stored, _ := srv.store.Get(id)
+	if stored.Category != "home" || stored.DueDate == nil || stored.Status != StatusInProgress {
+		t.Errorf("Expected only the category changed, got %+v", stored)
+	}
+
+	// An empty due date clears it
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/tasks/"+id, strings.NewReader(`{"due_date": ""}`)))
+	if stored, _ := srv.store.Get(id); rec.Code != http.StatusOK || stored.DueDate != nil {
+		t.Errorf("Expected the due date cleared, got %d and %v", rec.Code, stored.DueDate)
+	}
+}
+
+func TestServer_PatchValidation(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	if err := srv.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := srv.store.GetAll()[0].ID
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"status": "sleeping"}`, "invalid status: sleeping"},
+		{`{"description": "  "}`, "description is required"},
+		{`{"due_date": "tomorrow"}`, "invalid due_date: tomorrow"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/tasks/"+id, strings.NewReader(tt.body)))
+		var resp errorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode error response: %v", err)
+		}
+		if rec.Code != http.StatusBadRequest || resp.Error != tt.want {
+			t.Errorf("%s: expected 400 %q, got %d %q", tt.body, tt.want, rec.Code, resp.Error)
+		}
+	}
+
+	if task, _ := srv.store.Get(id); task.Status != StatusPending || task.Description != "Write report" {
+		t.Errorf("Expected the task untouched, got %+v", task)
+	}
+}
+
+func TestServer_Batch_CreateRequiresCategory(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	body := strings.NewReader(`[{"op": "create", "description": "No category"}]`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tasks/batch", body))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	resp := decodeBatch(t, rec)
+	if resp.Applied || !strings.Contains(resp.Results[0].Error, "category is required") {
+		t.Errorf("Expected the missing category reported, got %+v", resp.Results[0])
+	}
+	if len(srv.store.GetAll()) != 0 {
+		t.Errorf("Expected no tasks created, got %d", len(srv.store.GetAll()))
+	}
+}
+
+func TestServer_PatchEmptyBodyChangesNothing(t *testing.T) {
+	srv, h := newTestServer(t)
+
+	if err := srv.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	before := srv.store.GetAll()[0]
+	changes := srv.store.changes
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/tasks/"+before.ID, strings.NewReader(`{}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var task Task
+	if err := json.NewDecoder(rec.Body).Decode(&task); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if task.ID != before.ID || !task.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Errorf("Expected the task returned unchanged, got %+v", task)
+	}
+	if srv.store.changes != changes {
+		t.Error("Expected an empty PATCH not to count as a change")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/tasks/missing", strings.NewReader(`{}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing task, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Stats summarizes completion metrics over a set of tasks
+type Stats struct {
+	Total              int                  `json:"total"`
+	ByStatus           map[TaskStatus]int   `json:"by_status"`
+	ByCategory         map[TaskCategory]int `json:"by_category"`
+	CompletionRate     float64              `json:"completion_rate"`
+	AvgPendingAgeHours float64              `json:"avg_pending_age_hours"`
+	CompletedLast7Days int                  `json:"completed_last_7_days"`
+	CompletedPerDay    []DayCount           `json:"completed_per_day"`
+}
+
+// DayCount is how many tasks were completed on a calendar day
+type DayCount struct {
+	Day   time.Time `json:"day"` // local midnight
+	Count int       `json:"count"`
+}
+
+// chartDays is how many days the completion chart covers
+const chartDays = 7
+
+// chartWidth is the length of the longest bar in the completion chart
+const chartWidth = 20
+
+// computeStats aggregates metrics over tasks. An empty slice yields zeroed
+// metrics.
+func computeStats(tasks []Task) Stats {
+	now := time.Now()
+	weekAgo := now.AddDate(0, 0, -7)
+
+	stats := Stats{
+		Total: len(tasks),
+		ByStatus: map[TaskStatus]int{
+			StatusPending:    0,
+			StatusInProgress: 0,
+			StatusDone:       0,
+		},
+		ByCategory:      make(map[TaskCategory]int),
+		CompletedPerDay: completionsPerDay(tasks, now, chartDays),
+	}
+
+	var pendingAge time.Duration
+	for _, task := range tasks {
+		stats.ByStatus[task.Status]++
+		if task.Category != "" {
+			stats.ByCategory[task.Category]++
+		}
+
+		switch task.Status {
+		case StatusPending:
+			pendingAge += now.Sub(task.CreatedAt)
+		case StatusDone:
+			// Counted like the chart, so a later edit doesn't count again
+			if completed, _ := completedAt(task); completed.After(weekAgo) {
+				stats.CompletedLast7Days++
+			}
+		}
+	}
+
+	if stats.Total > 0 {
+		stats.CompletionRate = float64(stats.ByStatus[StatusDone]) / float64(stats.Total)
+	}
+	if pending := stats.ByStatus[StatusPending]; pending > 0 {
+		stats.AvgPendingAgeHours = pendingAge.Hours() / float64(pending)
+	}
+	return stats
+}
+
+// completedAt returns when a done task was completed: its last transition
+// to done, or its last update for tasks completed before history was kept
+func completedAt(task Task) (time.Time, bool) {
+	if task.Status != StatusDone {
+		return time.Time{}, false
+	}
+	for i := len(task.History) - 1; i >= 0; i-- {
+		if task.History[i].To == StatusDone {
+			return task.History[i].At, true
+		}
+	}
+	return task.UpdatedAt, true
+}
+
+// completionsPerDay counts the tasks completed on each of the last days
+// calendar days up to and including today, oldest first. Days without
+// completions count zero.
+func completionsPerDay(tasks []Task, now time.Time, days int) []DayCount {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	counts := make([]DayCount, days)
+	for i := range counts {
+		counts[i].Day = today.AddDate(0, 0, i-days+1)
+	}
+
+	for _, task := range tasks {
+		at, ok := completedAt(task)
+		if !ok {
+			continue
+		}
+		at = at.In(now.Location())
+		day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, now.Location())
+		// Count calendar days rather than 24h spans so DST changes don't
+		// shift a completion into the wrong bucket
+		for i := range counts {
+			if counts[i].Day.Equal(day) {
+				counts[i].Count++
+				break
+			}
+		}
+	}
+	return counts
+}
+
+// formatChart draws one bar per day, scaled so the busiest day fills
+// chartWidth; days without completions get an empty bar
+func formatChart(counts []DayCount) string {
+	highest := 0
+	for _, c := range counts {
+		highest = max(highest, c.Count)
+	}
+
+	barStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorDone))
+	var s strings.Builder
+	for _, c := range counts {
+		length := 0
+		if c.Count > 0 {
+			length = max(1, c.Count*chartWidth/highest)
+		}
+		bar := barStyle.Render(strings.Repeat("█", length))
+		fmt.Fprintf(&s, "  %s %s%s %d\n", c.Day.Format("Mon 01-02"), bar, strings.Repeat(" ", chartWidth-length), c.Count)
+	}
+	return s.String()
+}
+
+// formatStats renders stats for the terminal
+func formatStats(stats Stats) string {
+	var s strings.Builder
+
+	fmt.Fprintf(&s, "Total tasks:        %d\n", stats.Total)
+	for _, status := range []TaskStatus{StatusPending, StatusInProgress, StatusDone} {
+		fmt.Fprintf(&s, "  %-17s %d\n", string(status)+":", stats.ByStatus[status])
+	}
+	fmt.Fprintf(&s, "Completion rate:    %.1f%%\n", stats.CompletionRate*100)
+	fmt.Fprintf(&s, "Avg pending age:    %.1f days\n", stats.AvgPendingAgeHours/24)
+	fmt.Fprintf(&s, "Done last 7 days:   %d\n", stats.CompletedLast7Days)
+	if len(stats.CompletedPerDay) > 0 {
+		s.WriteString("\nCompleted per day:\n")
+		s.WriteString(formatChart(stats.CompletedPerDay))
+	}
+
+	if len(stats.ByCategory) > 0 {
+		categories := make([]string, 0, len(stats.ByCategory))
+		for category := range stats.ByCategory {
+			categories = append(categories, string(category))
+		}
+		sort.Strings(categories)
+
+		s.WriteString("\nBy category:\n")
+		for _, category := range categories {
+			fmt.Fprintf(&s, "  %-17s %d\n", category+":", stats.ByCategory[TaskCategory(category)])
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeStats_Empty(t *testing.T) {
+	stats := computeStats(nil)
+
+	if stats.Total != 0 {
+		t.Errorf("Expected 0 total, got %d", stats.Total)
+	}
+	if stats.CompletionRate != 0 || stats.AvgPendingAgeHours != 0 {
+		t.Errorf("Expected zeroed rates, got %+v", stats)
+	}
+	if math.IsNaN(stats.CompletionRate) {
+		t.Error("Completion rate should not be NaN")
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	now := time.Now()
+	tasks := []Task{
+		{Status: StatusPending, Category: "work", CreatedAt: now.Add(-48 * time.Hour)},
+		{Status: StatusPending, Category: "home", CreatedAt: now.Add(-24 * time.Hour)},
+		{Status: StatusInProgress, Category: "work", CreatedAt: now},
+		{Status: StatusDone, Category: "work", UpdatedAt: now.Add(-24 * time.Hour)},
+		{Status: StatusDone, UpdatedAt: now.AddDate(0, 0, -30)},
+	}
+
+	stats := computeStats(tasks)
+
+	if stats.Total != 5 {
+		t.Errorf("Expected 5 total, got %d", stats.Total)
+	}
+	if stats.ByStatus[StatusPending] != 2 || stats.ByStatus[StatusInProgress] != 1 || stats.ByStatus[StatusDone] != 2 {
+		t.Errorf("Unexpected status counts: %v", stats.ByStatus)
+	}
+	if stats.ByCategory["work"] != 3 || stats.ByCategory["home"] != 1 {
+		t.Errorf("Unexpected category counts: %v", stats.ByCategory)
+	}
+	if stats.CompletionRate != 0.4 {
+		t.Errorf("Expected completion rate 0.4, got %v", stats.CompletionRate)
+	}
+	if math.Abs(stats.AvgPendingAgeHours-36) > 0.1 {
+		t.Errorf("Expected average pending age ~36h, got %v", stats.AvgPendingAgeHours)
+	}
+	if stats.CompletedLast7Days != 1 {
+		t.Errorf("Expected 1 task completed in the last 7 days, got %d", stats.CompletedLast7Days)
+	}
+}
+
+func TestFormatStats(t *testing.T) {
+	out := formatStats(computeStats([]Task{{Status: StatusDone, Category: "work", UpdatedAt: time.Now()}}))
+
+	for _, want := range []string{"Total tasks:        1", "100.0%", "work:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestCompletionsPerDay(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	at := func(days, hour int) time.Time {
+		return time.Date(2026, 10, 16-days, hour, 30, 0, 0, time.Local)
+	}
+	tasks := []Task{
+		// Completed today and yesterday, by their last transition to done
+		{Status: StatusDone, History: []StatusChange{{From: StatusPending, To: StatusDone, At: at(3, 10)}, {From: StatusDone, To: StatusPending, At: at(2, 10)}, {From: StatusPending, To: StatusDone, At: at(0, 8)}}},
+		{Status: StatusDone, History: []StatusChange{{From: StatusPending, To: StatusDone, At: at(1, 23)}}},
+		// No history: falls back to UpdatedAt
+		{Status: StatusDone, UpdatedAt: at(1, 0)},
+		{Status: StatusDone, UpdatedAt: at(6, 12)},
+		// Outside the window or not done
+		{Status: StatusDone, UpdatedAt: at(7, 12)},
+		{Status: StatusPending, UpdatedAt: at(0, 8)},
+	}
+
+	counts := completionsPerDay(tasks, now, 7)
+	want := []int{1, 0, 0, 0, 0, 2, 1}
+	if len(counts) != len(want) {
+		t.Fatalf("Expected %d days, got %d", len(want), len(counts))
+	}
+	for i, c := range counts {
+		if c.Count != want[i] {
+			t.Errorf("Day %d (%s): expected %d, got %d", i, c.Day.Format(time.DateOnly), want[i], c.Count)
+		}
+	}
+	if first, last := counts[0].Day.Format(time.DateOnly), counts[6].Day.Format(time.DateOnly); first != "2026-10-10" || last != "2026-10-16" {
+		t.Errorf("Expected the days 2026-10-10 to 2026-10-16, got %s to %s", first, last)
+	}
+}
+
+func TestFormatChart(t *testing.T) {
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)
+	out := formatChart([]DayCount{{Day: day.AddDate(0, 0, -1), Count: 0}, {Day: day, Count: 4}})
+
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per day, got:\n%s", out)
+	}
+	if strings.Contains(lines[0], "█") || !strings.HasSuffix(lines[0], " 0") {
+		t.Errorf("Expected an empty bar for a day without completions, got %q", lines[0])
+	}
+	if strings.Count(lines[1], "█") != chartWidth || !strings.Contains(lines[1], "Fri 10-16") {
+		t.Errorf("Expected a full bar for the busiest day, got %q", lines[1])
+	}
+}
+
+func TestComputeStats_CompletedLast7DaysUsesCompletionTime(t *testing.T) {
+	now := time.Now()
+	monthAgo := now.AddDate(0, 0, -30)
+	tasks := []Task{
+		// Finished a month ago, edited today
+		{
+			Status:    StatusDone,
+			UpdatedAt: now,
+			History:   []StatusChange{{From: StatusPending, To: StatusDone, At: monthAgo}},
+		},
+	}
+
+	stats := computeStats(tasks)
+	if stats.CompletedLast7Days != 0 {
+		t.Errorf("Expected an old completion not to count, got %d", stats.CompletedLast7Days)
+	}
+	charted := 0
+	for _, day := range stats.CompletedPerDay {
+		charted += day.Count
+	}
+	if charted != stats.CompletedLast7Days {
+		t.Errorf("Expected the chart and the 7-day count to agree, got %d and %d", charted, stats.CompletedLast7Days)
+	}
+}
@@ -1,10 +1,23 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // TaskStatus represents the state of a task
@@ -16,47 +29,237 @@ const (
 	StatusDone       TaskStatus = "done"
 )
 
+// isValidStatus reports whether status is one of the known task states
+func isValidStatus(status TaskStatus) bool {
+	switch status {
+	case StatusPending, StatusInProgress, StatusDone:
+		return true
+	}
+	return false
+}
+
+// Priority ranks how urgent a task is; PriorityNone means it isn't set
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+// String returns the priority's name
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityMedium:
+		return "medium"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// StatusChange records a task moving from one status to another
+type StatusChange struct {
+	From TaskStatus `json:"from" yaml:"from"`
+	To   TaskStatus `json:"to" yaml:"to"`
+	At   time.Time  `json:"at" yaml:"at"`
+}
+
+// maxHistory caps how many status changes a task keeps; the oldest are
+// dropped first
+const maxHistory = 50
+
+// Storage formats for the tasks file
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
 // TaskCategory represents a task category
 type TaskCategory string
 
 // Task represents a single TODO item
 type Task struct {
-	ID          string       `json:"id"`
-	Description string       `json:"description"`
-	Status      TaskStatus   `json:"status"`
-	Category    TaskCategory `json:"category"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
+	ID            string         `json:"id" yaml:"id"`
+	Seq           int            `json:"seq,omitempty" yaml:"seq,omitempty"`
+	Description   string         `json:"description" yaml:"description"`
+	Status        TaskStatus     `json:"status" yaml:"status"`
+	Category      TaskCategory   `json:"category" yaml:"category"`
+	DueDate       *time.Time     `json:"due_date,omitempty" yaml:"due_date,omitempty"`
+	StartDate     *time.Time     `json:"start_date,omitempty" yaml:"start_date,omitempty"`
+	RemindAt      *time.Time     `json:"remind_at,omitempty" yaml:"remind_at,omitempty"`
+	Priority      Priority       `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Pinned        bool           `json:"pinned,omitempty" yaml:"pinned,omitempty"`
+	NextAction    bool           `json:"next_action,omitempty" yaml:"next_action,omitempty"`
+	Blocked       bool           `json:"blocked,omitempty" yaml:"blocked,omitempty"`
+	BlockedReason string         `json:"blocked_reason,omitempty" yaml:"blocked_reason,omitempty"`
+	Notes         string         `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Tags          []string       `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Links         []string       `json:"links,omitempty" yaml:"links,omitempty"`
+	History       []StatusChange `json:"history,omitempty" yaml:"history,omitempty"`
+	CreatedAt     time.Time      `json:"created_at" yaml:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" yaml:"updated_at"`
 }
 
-// TaskStore handles persistence of tasks
+// TaskStore handles persistence of tasks.
+// It is safe for concurrent use.
 type TaskStore struct {
+	mu       sync.RWMutex
 	filepath string
 	tasks    []Task
+	log      *taskLog // nil when using whole-file storage
+	firstRun bool     // no tasks file existed when the store was opened
+	minified bool     // write JSON without indentation
+
+	// With deferred saves, changes only bump changes and Flush writes
+	// them. saveMu serializes writes so an older snapshot can't land
+	// after a newer one.
+	deferred bool
+	changes  int // changes made so far
+	saved    int // changes written to disk
+	saveMu   sync.Mutex
+
+	// readOnly is set when the tasks file was written by a newer patodo.
+	// Saving would drop whatever this version doesn't understand, so
+	// every write fails with it instead.
+	readOnly error
+
+	// loadWarnings are the problems the last Load repaired, one line
+	// each, for the caller to report where its output goes
+	loadWarnings []string
 }
 
 // FilterOptions contains optional filter criteria
 type FilterOptions struct {
 	Status   *TaskStatus
 	Category *TaskCategory
+
+	// Actionable keeps only tasks that can be worked on now: not done
+	// and not blocked
+	Actionable bool
+
+	// DueBy keeps only tasks with a due date no later than this time
+	DueBy *time.Time
+
+	// StartedBy keeps only tasks without a start date or starting no
+	// later than this time
+	StartedBy *time.Time
+
+	// StalledBefore keeps only in-progress tasks last updated before this
+	// time
+	StalledBefore *time.Time
+
+	// UpdatedAfter keeps only tasks last updated after this time
+	UpdatedAfter *time.Time
+
+	// Query keeps only tasks matching this search text; see matchQuery
+	Query          string
+	QueryAllFields bool
 }
 
-// NewTaskStore creates a new task store
-func NewTaskStore() (*TaskStore, error) {
+// dataDir returns the patodo data directory, creating it if needed
+func dataDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+
+	dir := filepath.Join(homeDir, ".config", "patodo")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// localDirName is the directory holding a project's own tasks file, found
+// like .git by walking up from the working directory
+const localDirName = ".patodo"
+
+// findLocalDir returns the nearest .patodo directory in start or one of
+// its parents
+func findLocalDir(start string) (string, bool) {
+	dir := start
+	for {
+		candidate := filepath.Join(dir, localDirName)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
 	}
+}
 
-	dataDir := filepath.Join(homeDir, ".config", "patodo")
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
+// localTasksDir returns the project .patodo directory the working
+// directory is inside, if any
+func localTasksDir() (string, bool) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	return findLocalDir(cwd)
+}
+
+// tasksDir returns the directory for the tasks file: the project's
+// .patodo directory if the working directory is inside one, otherwise the
+// global data directory
+func tasksDir() (string, error) {
+	if dir, ok := localTasksDir(); ok {
+		return dir, nil
+	}
+	return dataDir()
+}
+
+// NewTaskStore creates a new task store using the configured backend, in
+// the project or global directory as chosen by tasksDir
+func NewTaskStore(cfg Config) (*TaskStore, error) {
+	dir, err := tasksDir()
+	if err != nil {
 		return nil, err
 	}
+	return newTaskStoreIn(cfg, dir)
+}
 
-	filePath := filepath.Join(dataDir, "tasks.json")
+// newTaskStoreIn creates a task store keeping its files in dir
+func newTaskStoreIn(cfg Config, dir string) (*TaskStore, error) {
+	var filePath string
+	switch cfg.StorageFormat {
+	case "", FormatJSON:
+		filePath = filepath.Join(dir, "tasks.json")
+	case FormatYAML:
+		filePath = filepath.Join(dir, "tasks.yaml")
+	default:
+		return nil, fmt.Errorf("unknown storage format %q", cfg.StorageFormat)
+	}
 	store := &TaskStore{
 		filepath: filePath,
 		tasks:    []Task{},
+		minified: cfg.CompactStorage,
+	}
+
+	switch cfg.StorageBackend {
+	case "", BackendFile:
+	case BackendLog:
+		store.log = newTaskLog(filePath, cfg.LogCompactEvery)
+		store.log.minified = cfg.CompactStorage
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+
+	// With no tasks file at all, this is the first time patodo runs
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		store.firstRun = true
+		if store.log != nil {
+			if _, err := os.Stat(store.log.path); err == nil {
+				store.firstRun = false
+			}
+		}
 	}
 
 	// Load existing tasks
@@ -65,6 +268,14 @@ func NewTaskStore() (*TaskStore, error) {
 		if !os.IsNotExist(err) {
 			return nil, err
 		}
+		if store.firstRun && cfg.StorageFormat == FormatYAML {
+			// Switching to YAML keeps existing JSON tasks; the next save
+			// writes them to tasks.yaml
+			if tasks, err := readTasksFile(filepath.Join(dir, "tasks.json")); err == nil {
+				store.tasks = tasks
+				store.firstRun = false
+			}
+		}
 	}
 
 	return store, nil
@@ -72,31 +283,260 @@ func NewTaskStore() (*TaskStore, error) {
 
 // Load reads tasks from disk
 func (s *TaskStore) Load() error {
-	data, err := os.ReadFile(s.filepath)
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tasks []Task
+	var err error
+	if s.log != nil {
+		tasks, err = s.log.load(s.filepath)
+	} else {
+		tasks, err = readTasksFile(s.filepath)
+	}
+	if errors.Is(err, errNewerVersion) {
+		s.readOnly = err
+	}
 	if err != nil {
 		return err
 	}
+	var warnings strings.Builder
+	repaired := repairDuplicateIDs(tasks, &warnings)
+	s.loadWarnings = nil
+	if warnings.Len() > 0 {
+		s.loadWarnings = strings.Split(strings.TrimSuffix(warnings.String(), "\n"), "\n")
+	}
+	numberTasks(tasks)
+	s.tasks = tasks
+	s.saved = s.changes
+	// A file this version understands can be saved again, even after an
+	// earlier load of a newer one
+	s.readOnly = nil
+	// The new IDs are written straight away, or each run would make up
+	// different ones and an ID one command printed would miss in the
+	// next. A failed write leaves them for the next save.
+	if repaired > 0 {
+		s.changes++
+		if err := s.write(s.tasks); err == nil {
+			s.saved = s.changes
+		}
+	}
+	return nil
+}
 
-	return json.Unmarshal(data, &s.tasks)
+// LoadWarnings returns the problems the last Load found and repaired, such
+// as duplicate task IDs
+func (s *TaskStore) LoadWarnings() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.loadWarnings)
 }
 
-// Save writes tasks to disk
+// Save writes tasks to disk, including any deferred changes
 func (s *TaskStore) Save() error {
-	data, err := json.MarshalIndent(s.tasks, "", "  ")
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.write(s.tasks); err != nil {
+		return err
+	}
+	s.saved = s.changes
+	return nil
+}
+
+// DeferSaves makes changes stay in memory until Flush is called, so the
+// caller can write them off its own goroutine
+func (s *TaskStore) DeferSaves() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deferred = true
+}
+
+// Unsaved reports whether there are deferred changes Flush hasn't written
+func (s *TaskStore) Unsaved() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.changes != s.saved
+}
+
+// Flush writes deferred changes to disk. The tasks are copied under the
+// read lock and written without holding it, so other goroutines can keep
+// reading and changing tasks during the write.
+func (s *TaskStore) Flush() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.RLock()
+	changes, saved := s.changes, s.saved
+	tasks := slices.Clone(s.tasks)
+	s.mu.RUnlock()
+	if changes == saved {
+		return nil
+	}
+
+	if err := s.write(tasks); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.saved = changes
+	s.mu.Unlock()
+	return nil
+}
+
+// save records a change and writes it to disk unless saves are deferred;
+// the caller must hold the lock
+func (s *TaskStore) save() error {
+	s.changes++
+	if s.deferred {
+		return nil
+	}
+	if err := s.write(s.tasks); err != nil {
+		return err
+	}
+	s.saved = s.changes
+	return nil
+}
+
+// write stores tasks in the backend. saveMu serializes writes: Save, Load
+// and Flush hold it, and the mutex guarding the tasks doesn't. Only save
+// writes without it, and only when saves aren't deferred, so Flush has
+// nothing to write alongside it.
+func (s *TaskStore) write(tasks []Task) error {
+	if s.readOnly != nil {
+		return s.readOnly
+	}
+	if s.log != nil {
+		return s.log.save(s.filepath, tasks)
+	}
+	return writeTasksFile(s.filepath, tasks, s.minified)
+}
+
+// isYAMLFile reports whether path names a YAML tasks file
+func isYAMLFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// currentFileVersion is the newest tasks file layout this binary reads
+const currentFileVersion = 1
+
+// errNewerVersion is returned when reading a tasks file written by a newer
+// patodo than this one
+var errNewerVersion = errors.New("tasks file is from a newer version of patodo")
+
+// taskFile is the versioned layout of a tasks file. A bare list of tasks,
+// as written so far, is version 1.
+type taskFile struct {
+	Version int    `json:"version" yaml:"version"`
+	Tasks   []Task `json:"tasks" yaml:"tasks"`
+}
+
+// readTasksFile reads a whole-file task list, as YAML when path has a
+// YAML extension and as JSON otherwise. Files with a version newer than
+// currentFileVersion are rejected rather than read with fields missing.
+func readTasksFile(path string) ([]Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file taskFile
+	unmarshal := json.Unmarshal
+	if isYAMLFile(path) {
+		unmarshal = yaml.Unmarshal
+	}
+	if isVersionedFile(data, isYAMLFile(path)) {
+		err = unmarshal(data, &file)
+	} else {
+		file.Version = 1
+		err = unmarshal(data, &file.Tasks)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if file.Version > currentFileVersion {
+		return nil, fmt.Errorf("%w: %s is version %d, this patodo reads up to version %d; upgrade patodo to use it",
+			errNewerVersion, path, file.Version, currentFileVersion)
+	}
+	return file.Tasks, nil
+}
+
+// isVersionedFile reports whether data holds a taskFile object rather
+// than a bare list of tasks
+func isVersionedFile(data []byte, isYAML bool) bool {
+	if !isYAML {
+		trimmed := bytes.TrimSpace(data)
+		return len(trimmed) > 0 && trimmed[0] == '{'
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return false
+	}
+	return doc.Content[0].Kind == yaml.MappingNode
+}
+
+// writeTasksFile writes tasks as a whole-file task list, in the format
+// readTasksFile expects for path. JSON is indented unless minified is set.
+func writeTasksFile(path string, tasks []Task, minified bool) error {
+	var data []byte
+	var err error
+	switch {
+	case isYAMLFile(path):
+		data, err = yaml.Marshal(tasks)
+	case minified:
+		data, err = json.Marshal(tasks)
+	default:
+		data, err = json.MarshalIndent(tasks, "", "  ")
+	}
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(s.filepath, data, 0644)
+	return os.WriteFile(path, data, 0644)
+}
+
+// Dir returns the directory holding the tasks file and its neighbours
+// (archive, log, config)
+func (s *TaskStore) Dir() string {
+	return filepath.Dir(s.filepath)
+}
+
+// IsFirstRun reports whether no tasks file existed when the store was opened
+func (s *TaskStore) IsFirstRun() bool {
+	return s.firstRun
 }
 
-// GetAll returns all tasks
+// GetAll returns a copy of all tasks
 func (s *TaskStore) GetAll() []Task {
-	return s.tasks
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := make([]Task, len(s.tasks))
+	copy(tasks, s.tasks)
+	return tasks
+}
+
+// Get returns the task with the given ID
+func (s *TaskStore) Get(id string) (Task, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if idx := s.findTaskIndex(id); idx != -1 {
+		return s.tasks[idx], true
+	}
+	return Task{}, false
 }
 
-// GetCategories returns a list of unique categories from all tasks
+// GetCategories returns a sorted list of unique categories from all tasks
 func (s *TaskStore) GetCategories() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	categorySet := make(map[string]struct{})
 	for _, task := range s.tasks {
 		if task.Category != "" {
@@ -108,12 +548,170 @@ func (s *TaskStore) GetCategories() []string {
 	for category := range categorySet {
 		categories = append(categories, category)
 	}
+	sort.Strings(categories)
 	return categories
 }
 
 // Add adds a new task
 func (s *TaskStore) Add(description string, category TaskCategory) error {
-	task := Task{
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task := newTask(description, category)
+	task.Seq = s.nextSeq()
+	s.tasks = append(s.tasks, task)
+	return s.save()
+}
+
+// nextSeq returns the sequence number for a new task: one more than the
+// highest in use. The caller must hold the lock.
+func (s *TaskStore) nextSeq() int {
+	highest := 0
+	for _, task := range s.tasks {
+		highest = max(highest, task.Seq)
+	}
+	return highest + 1
+}
+
+// numberTasks gives tasks saved before sequence numbers existed the next
+// free numbers, in list order
+func numberTasks(tasks []Task) {
+	highest := 0
+	for _, task := range tasks {
+		highest = max(highest, task.Seq)
+	}
+	for i := range tasks {
+		if tasks[i].Seq == 0 {
+			highest++
+			tasks[i].Seq = highest
+		}
+	}
+}
+
+// repairDuplicateIDs gives every task after the first with a given ID a new
+// ID, so lookups by ID can't reach the wrong task. A duplicate that also
+// shares the first task's number is renumbered by numberTasks. Each repair
+// is reported to warn; the new IDs are written with the next save.
+func repairDuplicateIDs(tasks []Task, warn io.Writer) int {
+	first := make(map[string]int, len(tasks))
+	repaired := 0
+	for i := range tasks {
+		j, seen := first[tasks[i].ID]
+		if !seen {
+			first[tasks[i].ID] = i
+			continue
+		}
+		id := generateID()
+		fmt.Fprintf(warn, "Warning: duplicate task ID %s, giving %q the new ID %s\n", tasks[i].ID, tasks[i].Description, id)
+		if tasks[i].Seq == tasks[j].Seq {
+			tasks[i].Seq = 0
+		}
+		tasks[i].ID = id
+		first[id] = i
+		repaired++
+	}
+	return repaired
+}
+
+// validateTask checks a task's description and, when requireCategory is
+// set, its category. Creating a task requires both; editing allows
+// clearing the category. The TUI and the HTTP API share these rules.
+func validateTask(description string, category TaskCategory, requireCategory bool) error {
+	if strings.TrimSpace(description) == "" {
+		return errors.New("description is required")
+	}
+	if requireCategory && strings.TrimSpace(string(category)) == "" {
+		return errors.New("category is required")
+	}
+	return nil
+}
+
+// errAmbiguousID is returned when an ID prefix matches more than one task
+var errAmbiguousID = errors.New("ambiguous task ID")
+
+// shortIDLength is how many hex digits a short task ID has
+const shortIDLength = 6
+
+// shortID returns a short, human-friendly reference for a task ID
+func shortID(id string) string {
+	sum := sha1.Sum([]byte(id))
+	return hex.EncodeToString(sum[:])[:shortIDLength]
+}
+
+// FindByPrefix returns the task whose short or full ID starts with prefix,
+// or the task numbered N for "#N". An exact full ID always wins; otherwise
+// the prefix must match exactly one task.
+func (s *TaskStore) FindByPrefix(prefix string) (Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return findByPrefix(s.tasks, prefix)
+}
+
+// findByPrefix implements FindByPrefix over any list of tasks
+func findByPrefix(tasks []Task, prefix string) (Task, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return Task{}, errTaskNotFound
+	}
+
+	if seq, ok := strings.CutPrefix(prefix, "#"); ok {
+		n, err := strconv.Atoi(seq)
+		if err == nil {
+			for _, task := range tasks {
+				if task.Seq == n {
+					return task, nil
+				}
+			}
+		}
+		return Task{}, fmt.Errorf("%w: %s", errTaskNotFound, prefix)
+	}
+
+	var matches []Task
+	for _, task := range tasks {
+		if task.ID == prefix {
+			return task, nil
+		}
+		if strings.HasPrefix(shortID(task.ID), prefix) || strings.HasPrefix(task.ID, prefix) {
+			matches = append(matches, task)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Task{}, fmt.Errorf("%w: %s", errTaskNotFound, prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return Task{}, fmt.Errorf("%w: %s matches %d tasks", errAmbiguousID, prefix, len(matches))
+	}
+}
+
+// normalizeDescription lowercases a description and collapses its
+// whitespace so near-identical descriptions compare equal
+func normalizeDescription(description string) string {
+	return strings.ToLower(strings.Join(strings.Fields(description), " "))
+}
+
+// findDuplicate returns a copy of an unfinished task in the same category
+// whose normalized description matches, or nil if there is none
+func (s *TaskStore) findDuplicate(description string, category TaskCategory) *Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	normalized := normalizeDescription(description)
+	for _, task := range s.tasks {
+		if task.Status != StatusDone && task.Category == category &&
+			normalizeDescription(task.Description) == normalized {
+			return &task
+		}
+	}
+	return nil
+}
+
+// newTask builds a pending task with a fresh ID
+func newTask(description string, category TaskCategory) Task {
+	return Task{
 		ID:          generateID(),
 		Description: description,
 		Status:      StatusPending,
@@ -121,12 +719,10 @@ func (s *TaskStore) Add(description string, category TaskCategory) error {
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
-
-	s.tasks = append(s.tasks, task)
-	return s.Save()
 }
 
-// findTaskIndex returns the index of a task by ID, or -1 if not found
+// findTaskIndex returns the index of a task by ID, or -1 if not found.
+// The caller must hold the lock.
 func (s *TaskStore) findTaskIndex(id string) int {
 	for i := range s.tasks {
 		if s.tasks[i].ID == id {
@@ -138,58 +734,576 @@ func (s *TaskStore) findTaskIndex(id string) int {
 
 // UpdateStatus updates the status of a task
 func (s *TaskStore) UpdateStatus(id string, status TaskStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if idx := s.findTaskIndex(id); idx != -1 {
-		s.tasks[idx].Status = status
-		s.tasks[idx].UpdatedAt = time.Now()
-		return s.Save()
+		setStatus(&s.tasks[idx], status, time.Now())
+		return s.save()
 	}
 	return nil
 }
 
+// setStatus changes a task's status, recording the change in its history
+// when the status actually differs
+func setStatus(task *Task, status TaskStatus, now time.Time) {
+	if task.Status != status {
+		// Copy so a rolled-back batch doesn't see the new entry
+		history := append(slices.Clone(task.History), StatusChange{From: task.Status, To: status, At: now})
+		if len(history) > maxHistory {
+			history = history[len(history)-maxHistory:]
+		}
+		task.History = history
+	}
+	task.Status = status
+	task.UpdatedAt = now
+}
+
 // UpdateDescription updates the description of a task
 func (s *TaskStore) UpdateDescription(id string, description string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if idx := s.findTaskIndex(id); idx != -1 {
 		s.tasks[idx].Description = description
 		s.tasks[idx].UpdatedAt = time.Now()
-		return s.Save()
+		return s.save()
+	}
+	return nil
+}
+
+// UpdateNotes updates the notes of a task
+func (s *TaskStore) UpdateNotes(id string, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx := s.findTaskIndex(id); idx != -1 {
+		s.tasks[idx].Notes = notes
+		s.tasks[idx].UpdatedAt = time.Now()
+		return s.save()
 	}
 	return nil
 }
 
+// AddLink adds a URL or file path to a task unless it already has it
+func (s *TaskStore) AddLink(id string, link string) error {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return errors.New("link is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.findTaskIndex(id)
+	if idx == -1 || slices.Contains(s.tasks[idx].Links, link) {
+		return nil
+	}
+	s.tasks[idx].Links = append(slices.Clone(s.tasks[idx].Links), link)
+	s.tasks[idx].UpdatedAt = time.Now()
+	return s.save()
+}
+
+// RemoveLink removes a link from a task. It returns an error if the task
+// doesn't have the link.
+func (s *TaskStore) RemoveLink(id string, link string) error {
+	link = strings.TrimSpace(link)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.findTaskIndex(id)
+	if idx == -1 {
+		return nil
+	}
+	if !slices.Contains(s.tasks[idx].Links, link) {
+		return fmt.Errorf("task has no link %q", link)
+	}
+	s.tasks[idx].Links = slices.DeleteFunc(slices.Clone(s.tasks[idx].Links), func(l string) bool { return l == link })
+	s.tasks[idx].UpdatedAt = time.Now()
+	return s.save()
+}
+
 // UpdateCategory updates the category of a task
 func (s *TaskStore) UpdateCategory(id string, category TaskCategory) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if idx := s.findTaskIndex(id); idx != -1 {
 		s.tasks[idx].Category = category
 		s.tasks[idx].UpdatedAt = time.Now()
-		return s.Save()
+		return s.save()
 	}
 	return nil
 }
 
 // Update updates both description and category of a task
 func (s *TaskStore) Update(id string, description string, category TaskCategory) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if idx := s.findTaskIndex(id); idx != -1 {
 		s.tasks[idx].Description = description
 		s.tasks[idx].Category = category
 		s.tasks[idx].UpdatedAt = time.Now()
-		return s.Save()
+		return s.save()
+	}
+	return nil
+}
+
+// TogglePin pins or unpins a task
+func (s *TaskStore) TogglePin(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx := s.findTaskIndex(id); idx != -1 {
+		s.tasks[idx].Pinned = !s.tasks[idx].Pinned
+		s.tasks[idx].UpdatedAt = time.Now()
+		return s.save()
+	}
+	return nil
+}
+
+// SetNextAction makes a task the single next action, clearing the flag from
+// every other task in the same save. An empty id clears it everywhere.
+func (s *TaskStore) SetNextAction(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := -1
+	if id != "" {
+		if idx = s.findTaskIndex(id); idx == -1 {
+			return fmt.Errorf("%w: %s", errTaskNotFound, id)
+		}
+	}
+
+	now := time.Now()
+	for i := range s.tasks {
+		if next := i == idx; s.tasks[i].NextAction != next {
+			s.tasks[i].NextAction = next
+			s.tasks[i].UpdatedAt = now
+		}
+	}
+	return s.save()
+}
+
+// Bury moves a task to the end of the task list, unpinned and with low
+// priority, in a single save. The list keeps this order when no sort is
+// active.
+func (s *TaskStore) Bury(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.findTaskIndex(id)
+	if idx == -1 {
+		return fmt.Errorf("%w: %s", errTaskNotFound, id)
+	}
+
+	task := s.tasks[idx]
+	task.Priority = PriorityLow
+	task.Pinned = false
+	task.UpdatedAt = time.Now()
+	tasks := append(slices.Clone(s.tasks[:idx]), s.tasks[idx+1:]...)
+	s.tasks = append(tasks, task)
+	return s.save()
+}
+
+// SetPriority sets the priority of a task
+func (s *TaskStore) SetPriority(id string, priority Priority) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx := s.findTaskIndex(id); idx != -1 {
+		s.tasks[idx].Priority = priority
+		s.tasks[idx].UpdatedAt = time.Now()
+		return s.save()
+	}
+	return nil
+}
+
+// SetBlocked marks a task as blocked with an optional reason, or clears it
+func (s *TaskStore) SetBlocked(id string, blocked bool, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx := s.findTaskIndex(id); idx != -1 {
+		s.tasks[idx].Blocked = blocked
+		s.tasks[idx].BlockedReason = ""
+		if blocked {
+			s.tasks[idx].BlockedReason = reason
+		}
+		s.tasks[idx].UpdatedAt = time.Now()
+		return s.save()
+	}
+	return nil
+}
+
+// SetStartDate sets or clears (nil) the date a task starts; until then it
+// can be hidden from the list
+func (s *TaskStore) SetStartDate(id string, start *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx := s.findTaskIndex(id); idx != -1 {
+		s.tasks[idx].StartDate = start
+		s.tasks[idx].UpdatedAt = time.Now()
+		return s.save()
+	}
+	return nil
+}
+
+// SetReminder sets or clears (nil) the time a task's reminder is due
+func (s *TaskStore) SetReminder(id string, at *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx := s.findTaskIndex(id); idx != -1 {
+		s.tasks[idx].RemindAt = at
+		s.tasks[idx].UpdatedAt = time.Now()
+		return s.save()
 	}
 	return nil
 }
 
 // Delete removes a task
 func (s *TaskStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if idx := s.findTaskIndex(id); idx != -1 {
 		s.tasks = append(s.tasks[:idx], s.tasks[idx+1:]...)
-		return s.Save()
+		return s.save()
 	}
 	return nil
 }
 
+// Rollover moves the due date of every unfinished task that is overdue as
+// of the given day forward to that day. Running it again on the same day
+// changes nothing. It returns the number of tasks moved.
+func (s *TaskStore) Rollover(to time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	today := startOfDay(to)
+	count := 0
+	for i := range s.tasks {
+		if isOverdue(s.tasks[i], today) {
+			due := today
+			s.tasks[i].DueDate = &due
+			s.tasks[i].UpdatedAt = time.Now()
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+	return count, s.save()
+}
+
+// BumpDueDate pushes a task's due date back by d, or sets it to d from the
+// start of today if it has none, and returns the new date. Whole days are
+// added on the calendar so DST changes don't shift the time of day.
+func (s *TaskStore) BumpDueDate(id string, d time.Duration) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.findTaskIndex(id)
+	if idx == -1 {
+		return time.Time{}, fmt.Errorf("%w: %s", errTaskNotFound, id)
+	}
+
+	base := startOfDay(time.Now())
+	if s.tasks[idx].DueDate != nil {
+		base = *s.tasks[idx].DueDate
+	}
+	due := base.Add(d)
+	if day := 24 * time.Hour; d%day == 0 {
+		due = base.AddDate(0, 0, int(d/day))
+	}
+	s.tasks[idx].DueDate = &due
+	s.tasks[idx].UpdatedAt = time.Now()
+	return due, s.save()
+}
+
+// isOverdue reports whether an unfinished task was due before the given day
+func isOverdue(task Task, day time.Time) bool {
+	return task.Status != StatusDone &&
+		task.DueDate != nil &&
+		task.DueDate.Before(startOfDay(day))
+}
+
+// isDueSoon reports whether an unfinished task is due after now but within
+// the given window
+func isDueSoon(task Task, now time.Time, window time.Duration) bool {
+	return task.Status != StatusDone &&
+		task.DueDate != nil &&
+		task.DueDate.After(now) &&
+		!task.DueDate.After(now.Add(window))
+}
+
+// isFutureStart reports whether a task's start date is still ahead
+func isFutureStart(task Task, now time.Time) bool {
+	return task.StartDate != nil && task.StartDate.After(now)
+}
+
+// isReminderDue reports whether an unfinished task's reminder time has
+// passed
+func isReminderDue(task Task, now time.Time) bool {
+	return task.Status != StatusDone &&
+		task.RemindAt != nil &&
+		!task.RemindAt.After(now)
+}
+
+// DueReminders returns the unfinished tasks whose reminder is due, oldest
+// reminder first
+func (s *TaskStore) DueReminders(now time.Time) []Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var due []Task
+	for _, task := range s.tasks {
+		if isReminderDue(task, now) {
+			due = append(due, task)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].RemindAt.Before(*due[j].RemindAt)
+	})
+	return due
+}
+
+// startOfDay returns midnight at the start of t's day, in t's location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// DeleteBatch removes all tasks with the given IDs in a single save and
+// returns how many were deleted
+func (s *TaskStore) DeleteBatch(ids []string) (int, error) {
+	count := 0
+	err := s.Batch(func(b *TaskBatch) error {
+		for _, id := range ids {
+			if b.Delete(id) {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// AddTagBatch adds a tag to several tasks with a single save and returns
+// how many didn't have it yet
+func (s *TaskStore) AddTagBatch(ids []string, tag string) (int, error) {
+	return s.tagBatch(ids, tag, (*TaskBatch).AddTag)
+}
+
+// RemoveTagBatch removes a tag from several tasks with a single save and
+// returns how many had it
+func (s *TaskStore) RemoveTagBatch(ids []string, tag string) (int, error) {
+	return s.tagBatch(ids, tag, (*TaskBatch).RemoveTag)
+}
+
+// tagBatch applies a TaskBatch tag change to each task and counts the
+// tasks it changed
+func (s *TaskStore) tagBatch(ids []string, tag string, change func(b *TaskBatch, id, tag string) bool) (int, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return 0, errors.New("tag is required")
+	}
+
+	count := 0
+	err := s.Batch(func(b *TaskBatch) error {
+		for _, id := range ids {
+			if change(b, id, tag) {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CompleteCategory marks every unfinished task in category as done with a
+// single save and returns how many were changed
+func (s *TaskStore) CompleteCategory(category TaskCategory) (int, error) {
+	count := 0
+	err := s.Batch(func(b *TaskBatch) error {
+		for _, task := range s.tasks {
+			if task.Category == category && task.Status != StatusDone {
+				b.UpdateStatus(task.ID, StatusDone)
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CategoryToTag adds the category's name as a tag to every task in it,
+// optionally clearing their category, and saves once. It returns the
+// number of tasks changed.
+func (s *TaskStore) CategoryToTag(category TaskCategory, clearCategory bool) (int, error) {
+	if category == "" {
+		return 0, errors.New("category is required")
+	}
+
+	count := 0
+	err := s.Batch(func(b *TaskBatch) error {
+		for _, task := range s.tasks {
+			if task.Category != category {
+				continue
+			}
+			b.AddTag(task.ID, string(category))
+			if clearCategory {
+				b.Update(task.ID, task.Description, "")
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// TaskBatch applies several changes to a store that are saved together.
+// It is only valid inside the function passed to TaskStore.Batch.
+type TaskBatch struct {
+	store *TaskStore
+}
+
+// Batch runs fn with exclusive access to the store and saves once when it
+// returns. If fn returns an error, all of its changes are rolled back and
+// nothing is saved.
+func (s *TaskStore) Batch(fn func(b *TaskBatch) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	original := make([]Task, len(s.tasks))
+	copy(original, s.tasks)
+
+	if err := fn(&TaskBatch{store: s}); err != nil {
+		s.tasks = original
+		return err
+	}
+	return s.save()
+}
+
+// Get returns the task with the given ID
+func (b *TaskBatch) Get(id string) (Task, bool) {
+	if idx := b.store.findTaskIndex(id); idx != -1 {
+		return b.store.tasks[idx], true
+	}
+	return Task{}, false
+}
+
+// Add adds a new task and returns it
+func (b *TaskBatch) Add(description string, category TaskCategory) Task {
+	task := newTask(description, category)
+	task.Seq = b.store.nextSeq()
+	b.store.tasks = append(b.store.tasks, task)
+	return task
+}
+
+// Update updates both description and category of a task.
+// It returns false if the task doesn't exist.
+func (b *TaskBatch) Update(id string, description string, category TaskCategory) bool {
+	idx := b.store.findTaskIndex(id)
+	if idx == -1 {
+		return false
+	}
+	b.store.tasks[idx].Description = description
+	b.store.tasks[idx].Category = category
+	b.store.tasks[idx].UpdatedAt = time.Now()
+	return true
+}
+
+// UpdateStatus updates the status of a task.
+// It returns false if the task doesn't exist.
+func (b *TaskBatch) UpdateStatus(id string, status TaskStatus) bool {
+	idx := b.store.findTaskIndex(id)
+	if idx == -1 {
+		return false
+	}
+	setStatus(&b.store.tasks[idx], status, time.Now())
+	return true
+}
+
+// SetDueDate sets or clears (nil) the due date of a task.
+// It returns false if the task doesn't exist.
+func (b *TaskBatch) SetDueDate(id string, due *time.Time) bool {
+	idx := b.store.findTaskIndex(id)
+	if idx == -1 {
+		return false
+	}
+	b.store.tasks[idx].DueDate = due
+	b.store.tasks[idx].UpdatedAt = time.Now()
+	return true
+}
+
+// AddTag adds a tag to a task unless it already has it.
+// It returns false if the task doesn't exist or already has the tag.
+func (b *TaskBatch) AddTag(id string, tag string) bool {
+	idx := b.store.findTaskIndex(id)
+	if idx == -1 {
+		return false
+	}
+	task := &b.store.tasks[idx]
+	if slices.Contains(task.Tags, tag) {
+		return false
+	}
+	// Copy so a rolled-back batch doesn't see the new tag
+	task.Tags = append(slices.Clone(task.Tags), tag)
+	task.UpdatedAt = time.Now()
+	return true
+}
+
+// RemoveTag removes a tag from a task.
+// It returns false if the task doesn't exist or doesn't have the tag.
+func (b *TaskBatch) RemoveTag(id string, tag string) bool {
+	idx := b.store.findTaskIndex(id)
+	if idx == -1 {
+		return false
+	}
+	task := &b.store.tasks[idx]
+	if !slices.Contains(task.Tags, tag) {
+		return false
+	}
+	task.Tags = slices.DeleteFunc(slices.Clone(task.Tags), func(t string) bool { return t == tag })
+	task.UpdatedAt = time.Now()
+	return true
+}
+
+// Delete removes a task. It returns false if the task doesn't exist.
+func (b *TaskBatch) Delete(id string) bool {
+	idx := b.store.findTaskIndex(id)
+	if idx == -1 {
+		return false
+	}
+	b.store.tasks = append(b.store.tasks[:idx], b.store.tasks[idx+1:]...)
+	return true
+}
+
 // Filter returns tasks matching the given criteria
 // If a filter option is nil, it's ignored
 func (s *TaskStore) Filter(opts FilterOptions) []Task {
-	var filtered []Task
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filtered := []Task{}
 	for _, task := range s.tasks {
 		// Check status filter
 		if opts.Status != nil && task.Status != *opts.Status {
@@ -201,12 +1315,52 @@ func (s *TaskStore) Filter(opts FilterOptions) []Task {
 			continue
 		}
 
+		if opts.Actionable && (task.Status == StatusDone || task.Blocked) {
+			continue
+		}
+
+		if opts.DueBy != nil && (task.DueDate == nil || task.DueDate.After(*opts.DueBy)) {
+			continue
+		}
+
+		if opts.StartedBy != nil && task.StartDate != nil && task.StartDate.After(*opts.StartedBy) {
+			continue
+		}
+
+		if opts.StalledBefore != nil && (task.Status != StatusInProgress || !task.UpdatedAt.Before(*opts.StalledBefore)) {
+			continue
+		}
+
+		if opts.UpdatedAfter != nil && !task.UpdatedAt.After(*opts.UpdatedAfter) {
+			continue
+		}
+
+		if _, ok := matchQuery(task, opts.Query, opts.QueryAllFields); !ok {
+			continue
+		}
+
 		filtered = append(filtered, task)
 	}
 	return filtered
 }
 
-// generateID creates a simple unique ID
+// lastIDTime is the timestamp behind the most recently generated ID
+var (
+	idMu       sync.Mutex
+	lastIDTime time.Time
+)
+
+// generateID creates a simple unique ID. IDs generated within the same
+// microsecond (e.g. during a bulk import) are bumped forward so they
+// never collide.
 func generateID() string {
-	return time.Now().Format("20060102150405.000000")
+	idMu.Lock()
+	defer idMu.Unlock()
+
+	now := time.Now().Truncate(time.Microsecond)
+	if !now.After(lastIDTime) {
+		now = lastIDTime.Add(time.Microsecond)
+	}
+	lastIDTime = now
+	return now.Format("20060102150405.000000")
 }
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestNewTaskStore(t *testing.T) {
-	store, err := NewTaskStore()
+	store, err := NewTaskStore(DefaultConfig())
 	if err != nil {
 		t.Fatalf("Failed to create task store: %v", err)
 	}
@@ -173,6 +178,31 @@ func TestTaskStore_Delete(t *testing.T) {
 	}
 }
 
+func TestTaskStore_DeleteBatch(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"Task 1", "Task 2", "Task 3"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := store.GetAll()
+
+	count, err := store.DeleteBatch([]string{tasks[0].ID, tasks[2].ID, "missing"})
+	if err != nil {
+		t.Fatalf("Failed to delete tasks: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 deleted tasks, got %d", count)
+	}
+
+	tasks = store.GetAll()
+	if len(tasks) != 1 || tasks[0].Description != "Task 2" {
+		t.Errorf("Expected only 'Task 2' to remain, got %+v", tasks)
+	}
+}
+
 func TestTaskStore_Filter_ByStatus(t *testing.T) {
 	store := setupTestStore(t)
 	defer cleanupTestStore(store)
@@ -292,6 +322,40 @@ func TestTaskStore_Filter_NoFilters(t *testing.T) {
 	}
 }
 
+func TestTaskStore_Filter_Actionable(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"Open", "Blocked", "Done"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := store.GetAll()
+	if err := store.SetBlocked(tasks[1].ID, true, "legal review"); err != nil {
+		t.Fatalf("Failed to block task: %v", err)
+	}
+	if err := store.UpdateStatus(tasks[2].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	filtered := store.Filter(FilterOptions{Actionable: true})
+	if len(filtered) != 1 || filtered[0].Description != "Open" {
+		t.Errorf("Expected only the open task, got %+v", filtered)
+	}
+
+	// Unblocking makes it actionable again and clears the reason
+	if err := store.SetBlocked(tasks[1].ID, false, ""); err != nil {
+		t.Fatalf("Failed to unblock task: %v", err)
+	}
+	if task, _ := store.Get(tasks[1].ID); task.BlockedReason != "" {
+		t.Errorf("Expected reason cleared, got '%s'", task.BlockedReason)
+	}
+	if len(store.Filter(FilterOptions{Actionable: true})) != 2 {
+		t.Error("Expected unblocked task to be actionable")
+	}
+}
+
 func TestTaskStore_GetCategories_Empty(t *testing.T) {
 	store := setupTestStore(t)
 	defer cleanupTestStore(store)
@@ -330,6 +394,29 @@ func TestTaskStore_GetCategories_Empty(t *testing.T) {
 	}
 }
 
+func TestTaskStore_GetCategories_Sorted(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, category := range []TaskCategory{"work", "home", "errands", "home"} {
+		if err := store.Add("Task", category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+
+	categories := store.GetCategories()
+	want := []string{"errands", "home", "work"}
+	if len(categories) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, categories)
+	}
+	for i := range want {
+		if categories[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, categories)
+			break
+		}
+	}
+}
+
 func TestTaskStore_SaveAndLoad(t *testing.T) {
 	store := setupTestStore(t)
 	defer cleanupTestStore(store)
@@ -358,6 +445,155 @@ func TestTaskStore_SaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestTaskStore_Rollover(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	today := time.Date(2025, 3, 10, 9, 30, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	store.tasks = []Task{
+		{ID: "overdue", Status: StatusPending, DueDate: &yesterday},
+		{ID: "overdue-wip", Status: StatusInProgress, DueDate: &yesterday},
+		{ID: "overdue-done", Status: StatusDone, DueDate: &yesterday},
+		{ID: "future", Status: StatusPending, DueDate: &tomorrow},
+		{ID: "no-due", Status: StatusPending},
+	}
+
+	count, err := store.Rollover(today)
+	if err != nil {
+		t.Fatalf("Rollover failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 tasks rolled over, got %d", count)
+	}
+
+	tasks := store.GetAll()
+	want := startOfDay(today)
+	for _, task := range tasks[:2] {
+		if !task.DueDate.Equal(want) {
+			t.Errorf("Expected %s due today, got %v", task.ID, task.DueDate)
+		}
+	}
+	if !tasks[2].DueDate.Equal(yesterday) {
+		t.Error("Done tasks should not be rolled over")
+	}
+	if !tasks[3].DueDate.Equal(tomorrow) {
+		t.Error("Future tasks should not be rolled over")
+	}
+	if tasks[4].DueDate != nil {
+		t.Error("Tasks without a due date should be untouched")
+	}
+
+	// Running again the same day is a no-op
+	count, err = store.Rollover(today.Add(8 * time.Hour))
+	if err != nil {
+		t.Fatalf("Second rollover failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected second rollover to be a no-op, got %d", count)
+	}
+}
+
+func TestTaskStore_Batch(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	err := store.Batch(func(b *TaskBatch) error {
+		task := b.Add("Task 1", "work")
+		b.UpdateStatus(task.ID, StatusDone)
+		b.Add("Task 2", "personal")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+
+	// Changes are persisted with the batch's single save
+	reloaded := &TaskStore{filepath: store.filepath}
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	tasks := reloaded.GetAll()
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].Status != StatusDone {
+		t.Errorf("Expected first task done, got '%s'", tasks[0].Status)
+	}
+}
+
+func TestTaskStore_Batch_Rollback(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if err := store.Add("Task 1", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := store.GetAll()[0].ID
+
+	err := store.Batch(func(b *TaskBatch) error {
+		b.Delete(id)
+		b.Add("Task 2", "work")
+		return errTaskNotFound
+	})
+	if err != errTaskNotFound {
+		t.Fatalf("Expected batch error to be returned, got %v", err)
+	}
+
+	tasks := store.GetAll()
+	if len(tasks) != 1 || tasks[0].ID != id {
+		t.Errorf("Expected rollback to restore the original task, got %+v", tasks)
+	}
+}
+
+func TestTaskStore_GetAllReturnsCopy(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if err := store.Add("Original", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	tasks := store.GetAll()
+	tasks[0].Description = "Mutated"
+
+	if store.GetAll()[0].Description != "Original" {
+		t.Error("Mutating the GetAll result should not change the store")
+	}
+}
+
+func TestTaskStore_ConcurrentAccess(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	// Run with -race to detect unsynchronized access
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if err := store.Add(fmt.Sprintf("Task %d", i), "work"); err != nil {
+				t.Errorf("Failed to add task: %v", err)
+			}
+			for _, task := range store.GetAll() {
+				_ = store.UpdateStatus(task.ID, StatusDone)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			_ = store.GetCategories()
+			_ = store.Filter(FilterOptions{})
+		}()
+	}
+	wg.Wait()
+
+	if len(store.GetAll()) != 10 {
+		t.Errorf("Expected 10 tasks, got %d", len(store.GetAll()))
+	}
+}
+
 func TestGenerateID(t *testing.T) {
 	id1 := generateID()
 	time.Sleep(1 * time.Millisecond)
@@ -371,6 +607,17 @@ func TestGenerateID(t *testing.T) {
 	}
 }
 
+func TestGenerateID_RapidCallsAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := generateID()
+		if seen[id] {
+			t.Fatalf("Duplicate ID generated: %s", id)
+		}
+		seen[id] = true
+	}
+}
+
 // Helper functions
 
 func setupTestStore(t *testing.T) *TaskStore {
@@ -388,3 +635,995 @@ func setupTestStore(t *testing.T) *TaskStore {
 func cleanupTestStore(store *TaskStore) {
 	_ = os.Remove(store.filepath)
 }
+
+func TestValidateTask(t *testing.T) {
+	tests := []struct {
+		name            string
+		description     string
+		category        TaskCategory
+		requireCategory bool
+		wantErr         string
+	}{
+		{"valid with category", "Buy milk", "home", true, ""},
+		{"empty description", "", "home", true, "description is required"},
+		{"blank description", "   ", "home", false, "description is required"},
+		{"missing required category", "Buy milk", "", true, "category is required"},
+		{"blank required category", "Buy milk", "  ", true, "category is required"},
+		{"optional category omitted", "Buy milk", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		err := validateTask(tt.description, tt.category, tt.requireCategory)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestTaskStore_YAMLRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	store := &TaskStore{filepath: path, tasks: []Task{}}
+
+	if err := store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := store.Add("Water plants", "home"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	tasks := store.GetAll()
+	if err := store.SetBlocked(tasks[0].ID, true, "QA sign-off"); err != nil {
+		t.Fatalf("Failed to block task: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read tasks file: %v", err)
+	}
+	if !strings.Contains(string(data), "description: Write report") {
+		t.Errorf("Expected YAML tasks file, got:\n%s", data)
+	}
+
+	reloaded := &TaskStore{filepath: path}
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Failed to load YAML tasks: %v", err)
+	}
+	got := reloaded.GetAll()
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(got))
+	}
+	if got[0].ID != tasks[0].ID || got[0].BlockedReason != "QA sign-off" || !got[0].Blocked {
+		t.Errorf("Expected first task to round-trip, got %+v", got[0])
+	}
+	if !got[1].CreatedAt.Equal(tasks[1].CreatedAt) {
+		t.Errorf("Expected created time %v, got %v", tasks[1].CreatedAt, got[1].CreatedAt)
+	}
+}
+
+func TestNewTaskStore_YAMLReadsExistingJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	jsonStore, err := NewTaskStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewTaskStore failed: %v", err)
+	}
+	if err := jsonStore.Add("Carried over", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.StorageFormat = FormatYAML
+	yamlStore, err := NewTaskStore(cfg)
+	if err != nil {
+		t.Fatalf("NewTaskStore failed: %v", err)
+	}
+	if filepath.Base(yamlStore.filepath) != "tasks.yaml" {
+		t.Errorf("Expected tasks.yaml, got %s", yamlStore.filepath)
+	}
+	tasks := yamlStore.GetAll()
+	if len(tasks) != 1 || tasks[0].Description != "Carried over" {
+		t.Fatalf("Expected JSON tasks to be read, got %+v", tasks)
+	}
+
+	if err := yamlStore.Save(); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if _, err := os.Stat(yamlStore.filepath); err != nil {
+		t.Errorf("Expected tasks.yaml to be written: %v", err)
+	}
+
+	cfg.StorageFormat = "toml"
+	if _, err := NewTaskStore(cfg); err == nil {
+		t.Error("Expected error for unknown storage format")
+	}
+}
+
+func TestTaskStore_FindDuplicate(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if err := store.Add("Buy milk", "home"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := store.Add("Call mom", "home"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := store.UpdateStatus(store.GetAll()[1].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	dup := store.findDuplicate("  buy   MILK ", "home")
+	if dup == nil || dup.Description != "Buy milk" {
+		t.Errorf("Expected normalized match on 'Buy milk', got %+v", dup)
+	}
+
+	if dup := store.findDuplicate("Buy milk", "work"); dup != nil {
+		t.Errorf("Expected no match in another category, got %+v", dup)
+	}
+	if dup := store.findDuplicate("Buy bread", "home"); dup != nil {
+		t.Errorf("Expected no match for a different description, got %+v", dup)
+	}
+	if dup := store.findDuplicate("Call mom", "home"); dup != nil {
+		t.Errorf("Expected done tasks to be ignored, got %+v", dup)
+	}
+}
+
+func TestTaskStore_CompleteCategory(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, tc := range []struct {
+		desc     string
+		category TaskCategory
+	}{{"Report", "work"}, {"Slides", "work"}, {"Groceries", "home"}} {
+		if err := store.Add(tc.desc, tc.category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := store.UpdateStatus(store.GetAll()[1].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	count, err := store.CompleteCategory("work")
+	if err != nil {
+		t.Fatalf("Failed to complete category: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 task completed, got %d", count)
+	}
+
+	for _, task := range store.GetAll() {
+		if task.Category == "work" && task.Status != StatusDone {
+			t.Errorf("Expected work task '%s' to be done", task.Description)
+		}
+		if task.Category == "home" && task.Status != StatusPending {
+			t.Errorf("Expected home task '%s' to be untouched, got %s", task.Description, task.Status)
+		}
+	}
+}
+
+func TestTaskStore_FindByPrefix(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	store.tasks = []Task{
+		{ID: "20260301120000.000001", Description: "First"},
+		{ID: "20260301120000.000002", Description: "Second"},
+	}
+
+	// Unique short ID prefix
+	short := shortID(store.tasks[1].ID)
+	if len(short) != shortIDLength {
+		t.Fatalf("Expected %d-char short ID, got %q", shortIDLength, short)
+	}
+	task, err := store.FindByPrefix(strings.ToUpper(short))
+	if err != nil {
+		t.Fatalf("Failed to find by short ID: %v", err)
+	}
+	if task.Description != "Second" {
+		t.Errorf("Expected 'Second', got '%s'", task.Description)
+	}
+
+	// Exact full ID
+	task, err = store.FindByPrefix("20260301120000.000001")
+	if err != nil || task.Description != "First" {
+		t.Errorf("Expected exact ID to find 'First', got %+v, %v", task, err)
+	}
+
+	// Shared full ID prefix is ambiguous
+	if _, err := store.FindByPrefix("20260301"); !errors.Is(err, errAmbiguousID) {
+		t.Errorf("Expected ambiguous ID error, got %v", err)
+	}
+
+	if _, err := store.FindByPrefix("zzz"); !errors.Is(err, errTaskNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestTaskStore_SeqAssignment(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"One", "Two", "Three"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+
+	tasks := store.GetAll()
+	for i, task := range tasks {
+		if task.Seq != i+1 {
+			t.Errorf("Expected task %q to have seq %d, got %d", task.Description, i+1, task.Seq)
+		}
+	}
+
+	// Deleting a task leaves the survivors' numbers alone
+	if err := store.Delete(tasks[0].ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	if err := store.Add("Four", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	newStore := &TaskStore{filepath: store.filepath, tasks: []Task{}}
+	if err := newStore.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	want := map[string]int{"Two": 2, "Three": 3, "Four": 4}
+	for _, task := range newStore.GetAll() {
+		if task.Seq != want[task.Description] {
+			t.Errorf("Expected %q to have seq %d after reload, got %d", task.Description, want[task.Description], task.Seq)
+		}
+	}
+
+	task, err := newStore.FindByPrefix("#3")
+	if err != nil || task.Description != "Three" {
+		t.Errorf("Expected #3 to find 'Three', got %+v, %v", task, err)
+	}
+	if _, err := newStore.FindByPrefix("#1"); !errors.Is(err, errTaskNotFound) {
+		t.Errorf("Expected deleted #1 to be not found, got %v", err)
+	}
+}
+
+func TestTaskStore_LoadNumbersOldTasks(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	data := `[{"id":"a","description":"Old one"},{"id":"b","description":"Numbered","seq":5},{"id":"c","description":"Old two"}]`
+	if err := os.WriteFile(store.filepath, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+	if err := store.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+
+	want := map[string]int{"Old one": 6, "Numbered": 5, "Old two": 7}
+	for _, task := range store.GetAll() {
+		if task.Seq != want[task.Description] {
+			t.Errorf("Expected %q to have seq %d, got %d", task.Description, want[task.Description], task.Seq)
+		}
+	}
+}
+
+func TestTaskStore_LoadRepairsDuplicateIDs(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	data := `[{"id":"a","description":"First","seq":1},{"id":"a","description":"Copy","seq":1},{"id":"b","description":"Other","seq":2}]`
+	if err := os.WriteFile(store.filepath, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+	if err := store.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+
+	tasks := store.GetAll()
+	if tasks[0].ID != "a" {
+		t.Errorf("Expected the first task to keep its ID, got %q", tasks[0].ID)
+	}
+	if tasks[1].ID == "a" || tasks[1].ID == "b" {
+		t.Errorf("Expected the duplicate to get a new ID, got %q", tasks[1].ID)
+	}
+	if tasks[1].Seq != 3 {
+		t.Errorf("Expected the duplicate to get the next number, got %d", tasks[1].Seq)
+	}
+	if warnings := store.LoadWarnings(); len(warnings) != 1 {
+		t.Errorf("Expected one load warning, got %q", warnings)
+	}
+
+	// Both tasks are now reachable on their own
+	if err := store.UpdateStatus(tasks[1].ID, StatusDone); err != nil {
+		t.Fatalf("UpdateStatus failed: %v", err)
+	}
+	if task, _ := store.Get("a"); task.Status == StatusDone {
+		t.Error("Expected updating the copy to leave the first task alone")
+	}
+}
+
+func TestRepairDuplicateIDs(t *testing.T) {
+	tasks := []Task{{ID: "a", Seq: 1}, {ID: "a", Seq: 2}, {ID: "a", Seq: 3}}
+	var warn strings.Builder
+	if n := repairDuplicateIDs(tasks, &warn); n != 2 {
+		t.Errorf("Expected 2 repairs, got %d", n)
+	}
+	if tasks[0].ID == tasks[1].ID || tasks[1].ID == tasks[2].ID || tasks[0].ID == tasks[2].ID {
+		t.Errorf("Expected unique IDs, got %q, %q, %q", tasks[0].ID, tasks[1].ID, tasks[2].ID)
+	}
+	if tasks[1].Seq != 2 || tasks[2].Seq != 3 {
+		t.Error("Expected distinct numbers to be kept")
+	}
+	if !strings.Contains(warn.String(), "Warning: duplicate task ID a") {
+		t.Errorf("Expected a warning, got %q", warn.String())
+	}
+}
+
+func TestTaskStore_Filter_UpdatedAfter(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	now := time.Now()
+	store.tasks = []Task{
+		{ID: "1", Description: "Old", Category: "work", Status: StatusPending, UpdatedAt: now.AddDate(0, 0, -10)},
+		{ID: "2", Description: "Recent", Category: "work", Status: StatusPending, UpdatedAt: now.AddDate(0, 0, -2)},
+		{ID: "3", Description: "Recent home", Category: "home", Status: StatusPending, UpdatedAt: now.Add(-time.Hour)},
+	}
+
+	after := now.AddDate(0, 0, -7)
+	tasks := store.Filter(FilterOptions{UpdatedAfter: &after})
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 recently updated tasks, got %d", len(tasks))
+	}
+
+	category := TaskCategory("work")
+	tasks = store.Filter(FilterOptions{UpdatedAfter: &after, Category: &category})
+	if len(tasks) != 1 || tasks[0].Description != "Recent" {
+		t.Errorf("Expected only 'Recent', got %+v", tasks)
+	}
+}
+
+func TestTaskStore_CategoryToTag(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, tt := range []struct {
+		desc     string
+		category TaskCategory
+	}{{"Milk", "errands"}, {"Bank", "errands"}, {"Report", "work"}} {
+		if err := store.Add(tt.desc, tt.category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+
+	count, err := store.CategoryToTag("errands", false)
+	if err != nil {
+		t.Fatalf("Failed to convert category: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 tasks tagged, got %d", count)
+	}
+	for _, task := range store.GetAll() {
+		tagged := slices.Contains(task.Tags, "errands")
+		if tagged != (task.Category == "errands") {
+			t.Errorf("Task %q: tagged=%v with category %q", task.Description, tagged, task.Category)
+		}
+	}
+
+	// Running it again with --clear doesn't duplicate tags
+	count, err = store.CategoryToTag("errands", true)
+	if err != nil || count != 2 {
+		t.Fatalf("Expected 2 tasks converted, got %d, %v", count, err)
+	}
+	for _, task := range store.GetAll()[:2] {
+		if task.Category != "" || len(task.Tags) != 1 {
+			t.Errorf("Expected %q to have no category and one tag, got %q %v", task.Description, task.Category, task.Tags)
+		}
+	}
+
+	if count, _ := store.CategoryToTag("errands", false); count != 0 {
+		t.Errorf("Expected no tasks left in the category, got %d", count)
+	}
+}
+
+func TestTaskStore_AddAndRemoveTagBatch(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"One", "Two", "Three"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := store.GetAll()
+
+	count, err := store.AddTagBatch([]string{tasks[0].ID}, "urgent")
+	if err != nil || count != 1 {
+		t.Fatalf("Expected 1 task tagged, got %d, %v", count, err)
+	}
+
+	// Tasks that already have the tag are left alone and not counted
+	count, err = store.AddTagBatch([]string{tasks[0].ID, tasks[1].ID}, " urgent ")
+	if err != nil || count != 1 {
+		t.Fatalf("Expected 1 newly tagged task, got %d, %v", count, err)
+	}
+	tasks = store.GetAll()
+	if !slices.Equal(tasks[0].Tags, []string{"urgent"}) || !slices.Equal(tasks[1].Tags, []string{"urgent"}) || len(tasks[2].Tags) != 0 {
+		t.Errorf("Expected only the first two tasks tagged once, got %v %v %v", tasks[0].Tags, tasks[1].Tags, tasks[2].Tags)
+	}
+
+	count, err = store.RemoveTagBatch([]string{tasks[1].ID, tasks[2].ID}, "urgent")
+	if err != nil || count != 1 {
+		t.Fatalf("Expected 1 task untagged, got %d, %v", count, err)
+	}
+	tasks = store.GetAll()
+	if len(tasks[0].Tags) != 1 || len(tasks[1].Tags) != 0 {
+		t.Errorf("Expected the tag removed from the second task only, got %v %v", tasks[0].Tags, tasks[1].Tags)
+	}
+
+	if _, err := store.AddTagBatch([]string{tasks[0].ID}, "  "); err == nil {
+		t.Error("Expected an error for an empty tag")
+	}
+}
+
+func TestTaskStore_CompactStorageRoundTrip(t *testing.T) {
+	load := func(minified bool) []Task {
+		t.Helper()
+		store := setupTestStore(t)
+		store.minified = minified
+		if err := store.Add("Write report", "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+		id := store.GetAll()[0].ID
+		if err := store.UpdateNotes(id, "line one\nline two"); err != nil {
+			t.Fatalf("Failed to update notes: %v", err)
+		}
+		store.tasks[0].CreatedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		store.tasks[0].UpdatedAt = store.tasks[0].CreatedAt
+		store.tasks[0].ID = "fixed"
+		if err := store.save(); err != nil {
+			t.Fatalf("Failed to save: %v", err)
+		}
+
+		data, err := os.ReadFile(store.filepath)
+		if err != nil {
+			t.Fatalf("Failed to read tasks file: %v", err)
+		}
+		if indented := strings.Contains(string(data), "\n  "); indented == minified {
+			t.Errorf("minified=%v: unexpected file layout:\n%s", minified, data)
+		}
+
+		reloaded := &TaskStore{filepath: store.filepath, tasks: []Task{}}
+		if err := reloaded.Load(); err != nil {
+			t.Fatalf("Failed to load tasks: %v", err)
+		}
+		return reloaded.GetAll()
+	}
+
+	pretty, compact := load(false), load(true)
+	if len(pretty) != 1 || len(compact) != 1 || !sameTask(pretty[0], compact[0]) {
+		t.Errorf("Expected identical tasks from both layouts, got %+v and %+v", pretty, compact)
+	}
+}
+
+func TestTaskStore_AddAndRemoveLink(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if err := store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := store.GetAll()[0].ID
+
+	for _, link := range []string{"https://example.com/spec", " ~/docs/report.md ", "https://example.com/spec"} {
+		if err := store.AddLink(id, link); err != nil {
+			t.Fatalf("Failed to add link: %v", err)
+		}
+	}
+	if err := store.AddLink(id, "   "); err == nil {
+		t.Error("Expected an error for an empty link")
+	}
+	want := []string{"https://example.com/spec", "~/docs/report.md"}
+	if got := store.GetAll()[0].Links; !slices.Equal(got, want) {
+		t.Errorf("Expected links %q, got %q", want, got)
+	}
+
+	// Links survive a reload
+	reloaded := &TaskStore{filepath: store.filepath, tasks: []Task{}}
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	if got := reloaded.GetAll()[0].Links; !slices.Equal(got, want) {
+		t.Errorf("Expected links %q after reload, got %q", want, got)
+	}
+
+	if err := store.RemoveLink(id, "https://example.com/spec"); err != nil {
+		t.Fatalf("Failed to remove link: %v", err)
+	}
+	if err := store.RemoveLink(id, "https://example.com/spec"); err == nil {
+		t.Error("Expected an error removing a link the task doesn't have")
+	}
+	if got := store.GetAll()[0].Links; !slices.Equal(got, []string{"~/docs/report.md"}) {
+		t.Errorf("Expected one link left, got %q", got)
+	}
+}
+
+func TestTaskStore_DeferredSaves(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+	store.DeferSaves()
+
+	if err := store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if !store.Unsaved() {
+		t.Error("Expected unsaved changes after a deferred add")
+	}
+	if _, err := os.Stat(store.filepath); !os.IsNotExist(err) {
+		t.Fatalf("Expected nothing written before Flush, got %v", err)
+	}
+
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	if store.Unsaved() {
+		t.Error("Expected no unsaved changes after Flush")
+	}
+	reloaded := &TaskStore{filepath: store.filepath, tasks: []Task{}}
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	if tasks := reloaded.GetAll(); len(tasks) != 1 || tasks[0].Description != "Write report" {
+		t.Errorf("Expected the flushed task on disk, got %+v", tasks)
+	}
+}
+
+func TestTaskStore_StatusHistory(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if err := store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := store.GetAll()[0].ID
+
+	for _, status := range []TaskStatus{StatusInProgress, StatusInProgress, StatusDone} {
+		if err := store.UpdateStatus(id, status); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+	}
+	if err := store.Batch(func(b *TaskBatch) error {
+		b.UpdateStatus(id, StatusPending)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to run batch: %v", err)
+	}
+
+	// Setting the same status again isn't a transition
+	want := [][2]TaskStatus{
+		{StatusPending, StatusInProgress},
+		{StatusInProgress, StatusDone},
+		{StatusDone, StatusPending},
+	}
+	reloaded := &TaskStore{filepath: store.filepath, tasks: []Task{}}
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	history := reloaded.GetAll()[0].History
+	if len(history) != len(want) {
+		t.Fatalf("Expected %d transitions, got %+v", len(want), history)
+	}
+	for i, change := range history {
+		if change.From != want[i][0] || change.To != want[i][1] {
+			t.Errorf("Transition %d: expected %s → %s, got %s → %s", i, want[i][0], want[i][1], change.From, change.To)
+		}
+		if i > 0 && change.At.Before(history[i-1].At) {
+			t.Errorf("Transition %d is out of order", i)
+		}
+	}
+}
+
+func TestTaskStore_StatusHistoryCapped(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if err := store.Add("Flaky task", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := store.GetAll()[0].ID
+	for i := 0; i < maxHistory+10; i++ {
+		status := StatusDone
+		if i%2 == 1 {
+			status = StatusPending
+		}
+		if err := store.UpdateStatus(id, status); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+	}
+
+	history := store.GetAll()[0].History
+	if len(history) != maxHistory {
+		t.Fatalf("Expected history capped at %d, got %d", maxHistory, len(history))
+	}
+	if last := history[len(history)-1]; last.To != StatusPending {
+		t.Errorf("Expected the newest transition kept, got %+v", last)
+	}
+}
+
+func TestTaskStore_LoadWithoutHistory(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	data := `[{"id":"old","description":"Old task","status":"done","category":"work","created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-01T00:00:00Z"}]`
+	if err := os.WriteFile(store.filepath, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+	if err := store.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	if history := store.GetAll()[0].History; len(history) != 0 {
+		t.Errorf("Expected no history for an old task, got %+v", history)
+	}
+}
+
+func TestTaskStore_LoadNewerVersion(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	future := `{"version": 99, "tasks": [{"id": "a", "description": "From the future", "status": "pending", "category": "work", "energy": "high"}]}`
+	if err := os.WriteFile(store.filepath, []byte(future), 0644); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+
+	err := store.Load()
+	if !errors.Is(err, errNewerVersion) {
+		t.Fatalf("Expected errNewerVersion, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "upgrade patodo") {
+		t.Errorf("Expected the error to suggest upgrading, got %q", err)
+	}
+
+	// The file is left alone by every kind of save
+	if err := store.Add("New task", "work"); !errors.Is(err, errNewerVersion) {
+		t.Errorf("Expected Add to refuse to save, got %v", err)
+	}
+	if err := store.Save(); !errors.Is(err, errNewerVersion) {
+		t.Errorf("Expected Save to refuse, got %v", err)
+	}
+	data, err := os.ReadFile(store.filepath)
+	if err != nil {
+		t.Fatalf("Failed to read tasks file: %v", err)
+	}
+	if string(data) != future {
+		t.Errorf("Expected the newer file untouched, got:\n%s", data)
+	}
+}
+
+func TestReadTasksFile_Versions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"bare.json":      `[{"id": "a", "description": "Bare"}]`,
+		"versioned.json": `{"version": 1, "tasks": [{"id": "a", "description": "Versioned"}]}`,
+		"bare.yaml":      "- id: a\n  description: Bare\n",
+		"versioned.yaml": "version: 1\ntasks:\n  - id: a\n    description: Versioned\n",
+		"future.yaml":    "version: 2\ntasks: []\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"bare.json", "versioned.json", "bare.yaml", "versioned.yaml"} {
+		tasks, err := readTasksFile(filepath.Join(dir, name))
+		if err != nil || len(tasks) != 1 || tasks[0].ID != "a" {
+			t.Errorf("%s: expected one task, got %+v, %v", name, tasks, err)
+		}
+	}
+	if _, err := readTasksFile(filepath.Join(dir, "future.yaml")); !errors.Is(err, errNewerVersion) {
+		t.Errorf("Expected errNewerVersion for a newer YAML file, got %v", err)
+	}
+}
+
+func TestTaskStore_Dir(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	if got, want := store.Dir(), filepath.Dir(store.filepath); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := store.archivePath(); filepath.Dir(got) != store.Dir() {
+		t.Errorf("Expected the archive in the data directory, got %q", got)
+	}
+}
+
+func TestTaskStore_BumpDueDate(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	due := time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local)
+	store.tasks = []Task{{ID: "dated", DueDate: &due}}
+
+	got, err := store.BumpDueDate("dated", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("BumpDueDate failed: %v", err)
+	}
+	if want := due.AddDate(0, 0, 1); !got.Equal(want) || !store.GetAll()[0].DueDate.Equal(want) {
+		t.Errorf("Expected due %v, got %v", want, got)
+	}
+
+	got, err = store.BumpDueDate("dated", 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("BumpDueDate failed: %v", err)
+	}
+	if want := due.AddDate(0, 0, 8); !got.Equal(want) {
+		t.Errorf("Expected due %v, got %v", want, got)
+	}
+
+	if _, err := store.BumpDueDate("missing", 24*time.Hour); !errors.Is(err, errTaskNotFound) {
+		t.Errorf("Expected errTaskNotFound, got %v", err)
+	}
+}
+
+func TestTaskStore_BumpDueDateWithoutDueDate(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	store.tasks = []Task{{ID: "undated"}}
+
+	got, err := store.BumpDueDate("undated", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("BumpDueDate failed: %v", err)
+	}
+	if want := startOfDay(time.Now()).AddDate(0, 0, 1); !got.Equal(want) {
+		t.Errorf("Expected due tomorrow (%v), got %v", want, got)
+	}
+	if store.GetAll()[0].DueDate == nil {
+		t.Error("Expected the due date to be saved on the task")
+	}
+}
+
+func TestFindLocalDir(t *testing.T) {
+	root := t.TempDir()
+	local := filepath.Join(root, localDirName)
+	nested := filepath.Join(root, "src", "pkg")
+	for _, dir := range []string{local, nested} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	for _, start := range []string{root, nested} {
+		if dir, ok := findLocalDir(start); !ok || dir != local {
+			t.Errorf("findLocalDir(%s) = %q, %v, want %q", start, dir, ok, local)
+		}
+	}
+}
+
+func TestFindLocalDir_NotFound(t *testing.T) {
+	root := t.TempDir()
+	// A file with the same name isn't a project directory
+	if err := os.WriteFile(filepath.Join(root, localDirName), nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if dir, ok := findLocalDir(root); ok && strings.HasPrefix(dir, root) {
+		t.Errorf("Expected no project directory, got %q", dir)
+	}
+}
+
+func TestTaskStore_SetNextAction(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"Write report", "Buy milk", "Call mom"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := store.GetAll()
+
+	nextActions := func() []string {
+		var ids []string
+		for _, task := range store.GetAll() {
+			if task.NextAction {
+				ids = append(ids, task.ID)
+			}
+		}
+		return ids
+	}
+
+	for _, task := range []Task{tasks[0], tasks[2], tasks[1]} {
+		if err := store.SetNextAction(task.ID); err != nil {
+			t.Fatalf("SetNextAction failed: %v", err)
+		}
+		if ids := nextActions(); len(ids) != 1 || ids[0] != task.ID {
+			t.Errorf("Expected only %s as the next action, got %v", task.ID, ids)
+		}
+	}
+
+	if err := store.SetNextAction(""); err != nil {
+		t.Fatalf("SetNextAction failed: %v", err)
+	}
+	if ids := nextActions(); len(ids) != 0 {
+		t.Errorf("Expected no next action, got %v", ids)
+	}
+
+	if err := store.SetNextAction("missing"); !errors.Is(err, errTaskNotFound) {
+		t.Errorf("Expected errTaskNotFound, got %v", err)
+	}
+}
+
+func TestTaskStore_Bury(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"Dreaded chore", "Write report", "Buy milk"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	id := store.GetAll()[0].ID
+	if err := store.SetPriority(id, PriorityHigh); err != nil {
+		t.Fatalf("SetPriority failed: %v", err)
+	}
+
+	changes := store.changes
+	if err := store.Bury(id); err != nil {
+		t.Fatalf("Bury failed: %v", err)
+	}
+	if store.changes != changes+1 {
+		t.Errorf("Expected a single save, got %d", store.changes-changes)
+	}
+
+	// Check what was written, not just memory
+	reloaded := &TaskStore{filepath: store.filepath, tasks: []Task{}}
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	tasks := reloaded.GetAll()
+	last := tasks[len(tasks)-1]
+	if last.ID != id || last.Priority != PriorityLow {
+		t.Errorf("Expected the buried task last with low priority, got %+v", last)
+	}
+	if tasks[0].Description != "Write report" || tasks[1].Description != "Buy milk" {
+		t.Errorf("Expected the other tasks to keep their order, got %q, %q", tasks[0].Description, tasks[1].Description)
+	}
+
+	if err := store.Bury("missing"); !errors.Is(err, errTaskNotFound) {
+		t.Errorf("Expected errTaskNotFound, got %v", err)
+	}
+}
+
+func TestTaskStore_DueReminders(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"Call the bank", "Water plants", "Renew passport", "Pay rent", "No reminder"} {
+		if err := store.Add(desc, "home"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := store.GetAll()
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.Local)
+	reminders := []time.Time{
+		now.Add(-time.Hour),      // due
+		now.Add(-24 * time.Hour), // due, and older
+		now.Add(time.Hour),       // not yet
+		now.Add(-time.Hour),      // due but the task is done
+	}
+	for i, at := range reminders {
+		if err := store.SetReminder(tasks[i].ID, &at); err != nil {
+			t.Fatalf("SetReminder failed: %v", err)
+		}
+	}
+	if err := store.UpdateStatus(tasks[3].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	due := store.DueReminders(now)
+	if len(due) != 2 {
+		t.Fatalf("Expected 2 due reminders, got %d", len(due))
+	}
+	if due[0].Description != "Water plants" || due[1].Description != "Call the bank" {
+		t.Errorf("Expected the oldest reminder first, got %q then %q", due[0].Description, due[1].Description)
+	}
+	if !isReminderDue(Task{Status: StatusPending, RemindAt: &now}, now) {
+		t.Error("Expected a reminder due exactly now to count")
+	}
+
+	if err := store.SetReminder(tasks[0].ID, nil); err != nil {
+		t.Fatalf("SetReminder failed: %v", err)
+	}
+	if got := store.DueReminders(now); len(got) != 1 {
+		t.Errorf("Expected the cleared reminder to be gone, got %d due", len(got))
+	}
+}
+
+func TestTaskStore_FilterStartedBy(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"No start", "Started", "Starts later"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := store.GetAll()
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.Local)
+	past, future := now.AddDate(0, 0, -1), now.AddDate(0, 0, 1)
+	if err := store.SetStartDate(tasks[1].ID, &past); err != nil {
+		t.Fatalf("SetStartDate failed: %v", err)
+	}
+	if err := store.SetStartDate(tasks[2].ID, &future); err != nil {
+		t.Fatalf("SetStartDate failed: %v", err)
+	}
+
+	filtered := store.Filter(FilterOptions{StartedBy: &now})
+	if len(filtered) != 2 || filtered[0].Description != "No start" || filtered[1].Description != "Started" {
+		t.Errorf("Expected the future task left out, got %+v", filtered)
+	}
+	if got := store.Filter(FilterOptions{}); len(got) != 3 {
+		t.Errorf("Expected every task without StartedBy, got %d", len(got))
+	}
+}
+
+func TestTaskStore_LoadAfterNewerVersionAllowsSaves(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	future := `{"version": 99, "tasks": [{"id": "a", "description": "From the future", "status": "pending", "category": "work"}]}`
+	if err := os.WriteFile(store.filepath, []byte(future), 0644); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+	if err := store.Load(); !errors.Is(err, errNewerVersion) {
+		t.Fatalf("Expected errNewerVersion, got %v", err)
+	}
+
+	// Replacing the file with one this version wrote, like switching back
+	// to a valid list, makes the store writable again
+	if err := writeTasksFile(store.filepath, []Task{newTask("Write report", "work")}, false); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+	if err := store.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	if err := store.Add("Buy milk", "home"); err != nil {
+		t.Errorf("Expected saves to work after a normal load, got %v", err)
+	}
+	if err := store.Save(); err != nil {
+		t.Errorf("Expected Save to work after a normal load, got %v", err)
+	}
+}
+
+func TestTaskStore_RepairedIDsSurviveReload(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	data := `[{"id":"a","description":"First","seq":1},{"id":"a","description":"Copy","seq":1}]`
+	if err := os.WriteFile(store.filepath, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+	if err := store.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	first := store.GetAll()
+
+	// A second run must find the same IDs, not make up new ones
+	if err := store.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	second := store.GetAll()
+	if first[1].ID != second[1].ID {
+		t.Errorf("Expected the repaired ID to be kept, got %q then %q", first[1].ID, second[1].ID)
+	}
+	if warnings := store.LoadWarnings(); len(warnings) != 0 {
+		t.Errorf("Expected nothing left to repair, got %q", warnings)
+	}
+	if store.Unsaved() {
+		t.Error("Expected the repair to be written")
+	}
+}
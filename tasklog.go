@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Storage backends selectable via config
+const (
+	BackendFile = "file"
+	BackendLog  = "log"
+)
+
+// logOp identifies the kind of change recorded in the event log
+type logOp string
+
+const (
+	opCreate logOp = "create"
+	opUpdate logOp = "update"
+	opDelete logOp = "delete"
+)
+
+// logEvent is a single line of the event log
+type logEvent struct {
+	Op   logOp  `json:"op"`
+	ID   string `json:"id"`
+	Task *Task  `json:"task,omitempty"`
+}
+
+// taskLog persists tasks as a snapshot file plus an append-only log of
+// events. Replaying the log over the snapshot reconstructs the current state.
+type taskLog struct {
+	path         string
+	compactEvery int    // compact once the log holds more events than this
+	persisted    []Task // state represented by snapshot + log
+	entries      int    // events in the log since the last compaction
+	minified     bool   // write the snapshot without indentation
+}
+
+// newTaskLog creates an event log stored next to the given snapshot file
+func newTaskLog(snapshotPath string, compactEvery int) *taskLog {
+	base := strings.TrimSuffix(snapshotPath, filepath.Ext(snapshotPath))
+	return &taskLog{
+		path:         base + ".log.jsonl",
+		compactEvery: compactEvery,
+	}
+}
+
+// load reads the snapshot and replays the event log on top of it
+func (l *taskLog) load(snapshotPath string) ([]Task, error) {
+	tasks, err := readTasksFile(snapshotPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if tasks == nil {
+		tasks = []Task{}
+	}
+
+	data, err := os.ReadFile(l.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	entries := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var event logEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, err
+		}
+		tasks = applyEvent(tasks, event)
+		entries++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	l.persisted = append([]Task(nil), tasks...)
+	l.entries = entries
+	return tasks, nil
+}
+
+// save records the difference between the persisted state and tasks,
+// compacting into a fresh snapshot when the log grows too long
+func (l *taskLog) save(snapshotPath string, tasks []Task) error {
+	events, reordered := diffEvents(l.persisted, tasks)
+	if len(events) == 0 && !reordered {
+		return nil
+	}
+
+	if reordered || l.entries+len(events) > l.compactEvery {
+		return l.compact(snapshotPath, tasks)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	l.persisted = append([]Task(nil), tasks...)
+	l.entries += len(events)
+	return nil
+}
+
+// compact writes tasks as the new snapshot and truncates the log. The
+// snapshot is swapped in whole before the log goes, so a crash in between
+// leaves a log whose replay over the new snapshot changes nothing.
+func (l *taskLog) compact(snapshotPath string, tasks []Task) error {
+	// The temp name keeps the extension, which picks the file format
+	tmp := filepath.Join(filepath.Dir(snapshotPath), ".tmp-"+filepath.Base(snapshotPath))
+	if err := writeTasksFile(tmp, tasks, l.minified); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, snapshotPath); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	l.persisted = append([]Task(nil), tasks...)
+	l.entries = 0
+	return nil
+}
+
+// applyEvent replays a single event onto tasks. Replaying an event that
+// is already in tasks changes nothing, as after a crash mid-compaction.
+func applyEvent(tasks []Task, event logEvent) []Task {
+	switch event.Op {
+	case opCreate:
+		exists := slices.ContainsFunc(tasks, func(task Task) bool { return task.ID == event.ID })
+		if event.Task != nil && !exists {
+			tasks = append(tasks, *event.Task)
+		}
+	case opUpdate:
+		for i := range tasks {
+			if tasks[i].ID == event.ID && event.Task != nil {
+				tasks[i] = *event.Task
+				break
+			}
+		}
+	case opDelete:
+		for i := range tasks {
+			if tasks[i].ID == event.ID {
+				tasks = append(tasks[:i], tasks[i+1:]...)
+				break
+			}
+		}
+	}
+	return tasks
+}
+
+// diffEvents returns the events that turn old into new. reordered is true
+// when new's ordering can't be reproduced by replaying the events, in which
+// case a compaction is required.
+func diffEvents(old, new []Task) (events []logEvent, reordered bool) {
+	oldByID := make(map[string]Task, len(old))
+	for _, task := range old {
+		oldByID[task.ID] = task
+	}
+	newIDs := make(map[string]struct{}, len(new))
+	for _, task := range new {
+		newIDs[task.ID] = struct{}{}
+	}
+
+	// Replay keeps survivors in their old order and appends creates
+	var expected []string
+	for _, task := range old {
+		if _, ok := newIDs[task.ID]; ok {
+			expected = append(expected, task.ID)
+		} else {
+			events = append(events, logEvent{Op: opDelete, ID: task.ID})
+		}
+	}
+
+	for _, task := range new {
+		task := task
+		prev, ok := oldByID[task.ID]
+		if !ok {
+			expected = append(expected, task.ID)
+			events = append(events, logEvent{Op: opCreate, ID: task.ID, Task: &task})
+			continue
+		}
+		if !sameTask(prev, task) {
+			events = append(events, logEvent{Op: opUpdate, ID: task.ID, Task: &task})
+		}
+	}
+
+	if len(expected) != len(new) {
+		return events, true
+	}
+	for i, task := range new {
+		if expected[i] != task.ID {
+			return events, true
+		}
+	}
+	return events, false
+}
+
+// sameTask reports whether two tasks serialize identically
+func sameTask(a, b Task) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setupLogStore(t *testing.T, compactEvery int) *TaskStore {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	return &TaskStore{
+		filepath: path,
+		tasks:    []Task{},
+		log:      newTaskLog(path, compactEvery),
+	}
+}
+
+func reopenLogStore(t *testing.T, store *TaskStore) *TaskStore {
+	t.Helper()
+
+	reopened := &TaskStore{
+		filepath: store.filepath,
+		tasks:    []Task{},
+		log:      newTaskLog(store.filepath, store.log.compactEvery),
+	}
+	if err := reopened.Load(); err != nil {
+		t.Fatalf("Failed to reload store: %v", err)
+	}
+	return reopened
+}
+
+func TestTaskLog_Replay(t *testing.T) {
+	store := setupLogStore(t, 100)
+
+	if err := store.Add("Task 1", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := store.Add("Task 2", "personal"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := store.Add("Task 3", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	tasks := store.GetAll()
+	if err := store.UpdateStatus(tasks[0].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if err := store.Delete(tasks[1].ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+
+	// Nothing compacted yet, so the snapshot shouldn't exist
+	if _, err := os.Stat(store.filepath); !os.IsNotExist(err) {
+		t.Errorf("Expected no snapshot before compaction, got err=%v", err)
+	}
+
+	reloaded := reopenLogStore(t, store).GetAll()
+	if len(reloaded) != 2 {
+		t.Fatalf("Expected 2 tasks after replay, got %d", len(reloaded))
+	}
+	if reloaded[0].ID != tasks[0].ID || reloaded[0].Status != StatusDone {
+		t.Errorf("Expected first task done after replay, got %+v", reloaded[0])
+	}
+	if reloaded[1].ID != tasks[2].ID {
+		t.Errorf("Expected third task second after replay, got %+v", reloaded[1])
+	}
+}
+
+func TestTaskLog_Compaction(t *testing.T) {
+	store := setupLogStore(t, 3)
+
+	for _, desc := range []string{"Task 1", "Task 2", "Task 3"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if _, err := os.Stat(store.log.path); err != nil {
+		t.Fatalf("Expected log file before compaction: %v", err)
+	}
+
+	// The fourth event exceeds the limit and triggers compaction
+	if err := store.Add("Task 4", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if _, err := os.Stat(store.log.path); !os.IsNotExist(err) {
+		t.Errorf("Expected log to be truncated after compaction, got err=%v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(store.filepath), ".tmp-tasks.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected the temp snapshot renamed into place, got err=%v", err)
+	}
+
+	snapshot, err := readTasksFile(store.filepath)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+	if len(snapshot) != 4 {
+		t.Errorf("Expected 4 tasks in snapshot, got %d", len(snapshot))
+	}
+
+	// Further changes append to a fresh log on top of the snapshot
+	if err := store.UpdateDescription(snapshot[0].ID, "Renamed"); err != nil {
+		t.Fatalf("Failed to update description: %v", err)
+	}
+
+	reloaded := reopenLogStore(t, store).GetAll()
+	if len(reloaded) != 4 {
+		t.Fatalf("Expected 4 tasks after reload, got %d", len(reloaded))
+	}
+	if reloaded[0].Description != "Renamed" {
+		t.Errorf("Expected replayed rename, got '%s'", reloaded[0].Description)
+	}
+}
+
+func TestDiffEvents_Reorder(t *testing.T) {
+	a := Task{ID: "a", Description: "A"}
+	b := Task{ID: "b", Description: "B"}
+
+	events, reordered := diffEvents([]Task{a, b}, []Task{a, b, {ID: "c"}})
+	if reordered {
+		t.Error("Appending a task should not count as a reorder")
+	}
+	if len(events) != 1 || events[0].Op != opCreate {
+		t.Errorf("Expected a single create event, got %+v", events)
+	}
+
+	if _, reordered := diffEvents([]Task{a, b}, []Task{b, a}); !reordered {
+		t.Error("Swapping tasks should require compaction")
+	}
+}
+
+func TestTaskLog_CrashDuringCompaction(t *testing.T) {
+	store := setupLogStore(t, 100)
+
+	for _, desc := range []string{"Task 1", "Task 2"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := store.UpdateStatus(store.GetAll()[0].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	// Compaction wrote the snapshot but died before removing the log
+	if err := writeTasksFile(store.filepath, store.GetAll(), false); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+
+	reloaded := reopenLogStore(t, store).GetAll()
+	if len(reloaded) != 2 {
+		t.Fatalf("Expected 2 tasks after replaying the stale log, got %d", len(reloaded))
+	}
+	if reloaded[0].Status != StatusDone {
+		t.Errorf("Expected the first task still done, got %s", reloaded[0].Status)
+	}
+}
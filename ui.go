@@ -1,12 +1,22 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // ViewMode represents different views in the TUI
@@ -18,6 +28,20 @@ const (
 	ModeEdit
 	ModeFilter
 	ModeFilterCategory
+	ModeRename
+	ModeBlockReason
+	ModeConfirm
+	ModeFilterBuilder
+	ModeSearch
+	ModeNotes
+	ModeDetail
+	ModeTag
+	ModeCategoryPicker
+	ModeLink
+	ModeTypeAhead
+	ModeReminder
+	ModeStartDate
+	ModeCommand
 )
 
 // Color constants
@@ -30,39 +54,245 @@ const (
 	colorPending    = "250"
 	colorInProgress = "214"
 	colorDone       = "34"
+	colorWarning    = "214"
+
+	colorPriorityLow    = "39"
+	colorPriorityMedium = "220"
+	colorPriorityHigh   = "196"
+)
+
+// namedColors maps basic color names accepted in the config to ANSI codes
+var namedColors = map[string]string{
+	"black":   "0",
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+}
+
+// statusGroupOrder is the default order of status groups in the grouped
+// view; the group_order config setting replaces it
+var statusGroupOrder = []TaskStatus{StatusInProgress, StatusPending, StatusDone}
+
+// defaultStatusIcons are the status icons used unless status_icons
+// replaces them
+var defaultStatusIcons = map[TaskStatus]string{
+	StatusPending:    "○",
+	StatusInProgress: "⟳",
+	StatusDone:       "✓",
+}
+
+// maxStatusIconWidth is the widest icon that fits the table's status column
+const maxStatusIconWidth = 3
+
+// validateStatusIcons checks that each configured icon is for a known
+// status and fits the status column
+func validateStatusIcons(icons map[TaskStatus]string) error {
+	for status, icon := range icons {
+		if !isValidStatus(status) {
+			return fmt.Errorf("unknown status %q", status)
+		}
+		if strings.TrimSpace(icon) == "" {
+			return fmt.Errorf("the icon for %s is empty", status)
+		}
+		if lipgloss.Width(icon) > maxStatusIconWidth {
+			return fmt.Errorf("the icon for %s is wider than %d columns", status, maxStatusIconWidth)
+		}
+	}
+	return nil
+}
+
+// validateGroupOrder checks that order lists each known status exactly once
+func validateGroupOrder(order []TaskStatus) error {
+	seen := make(map[TaskStatus]bool)
+	for _, status := range order {
+		if !isValidStatus(status) {
+			return fmt.Errorf("unknown status %q", status)
+		}
+		if seen[status] {
+			return fmt.Errorf("%q is listed twice", status)
+		}
+		seen[status] = true
+	}
+	if len(seen) != len(statusGroupOrder) {
+		return fmt.Errorf("must list all of %v", statusGroupOrder)
+	}
+	return nil
+}
+
+// maxFilterHistory caps how many previous filters can be restored
+const maxFilterHistory = 10
+
+// tableCreatedMinWidth is the terminal width needed to fit the Created
+// column next to the rest of the table
+const tableCreatedMinWidth = 99
+
+// minWidth and minHeight are the smallest terminal the TUI draws into;
+// anything smaller gets a resize warning instead
+const (
+	minWidth  = 20
+	minHeight = 6
+)
+
+// dueWindow limits tasks by how soon they're due
+type dueWindow int
+
+const (
+	DueAny dueWindow = iota
+	DueOverdue
+	DueToday
+	DueThisWeek
+)
+
+// String returns the label shown for a due window
+func (d dueWindow) String() string {
+	switch d {
+	case DueOverdue:
+		return "overdue"
+	case DueToday:
+		return "due today"
+	case DueThisWeek:
+		return "due this week"
+	}
+	return "any"
+}
+
+// dueBy returns the latest due time included by the window relative to
+// now, or nil for DueAny
+func (d dueWindow) dueBy(now time.Time) *time.Time {
+	var days int
+	switch d {
+	case DueOverdue:
+		days = 0
+	case DueToday:
+		days = 1
+	case DueThisWeek:
+		days = 7
+	default:
+		return nil
+	}
+	by := startOfDay(now).AddDate(0, 0, days).Add(-time.Nanosecond)
+	return &by
+}
+
+// recentWindow is how far back the "recently updated" filter looks
+const recentWindow = 7 * 24 * time.Hour
+
+// sortKeys lists the task orders DefaultSort accepts; "" keeps insertion
+// order
+var sortKeys = []string{"", "created", "updated", "due", "description", "status", "category"}
+
+// filterState captures the active filters so they can be restored later
+type filterState struct {
+	status     *TaskStatus
+	category   *TaskCategory
+	actionable bool
+	due        dueWindow
+	stalled    bool
+	recent     bool
+}
+
+// Filter builder fields, in display order
+const (
+	builderStatus = iota
+	builderCategory
+	builderActionable
+	builderDue
+	builderFieldCount
 )
 
+// confirmation is an action waiting for the user to answer y/n
+type confirmation struct {
+	prompt string
+	run    func(m *model) // executed when the user confirms
+	seq    int            // tells this confirmation's timeout from older ones
+}
+
+// confirmTimeoutMsg cancels the confirmation with the same seq if it's
+// still waiting for an answer
+type confirmTimeoutMsg struct {
+	seq int
+}
+
 // Model holds the application state
 type model struct {
-	store          *TaskStore
-	tasks          []Task
-	cursor         int
-	viewMode       ViewMode
-	textInput      textinput.Model
-	categoryInput  textinput.Model
-	filterStatus   *TaskStatus
-	filterCategory *TaskCategory
-	message        string
-	quitting       bool
-	activeInput    int    // 0 for description, 1 for category
-	editingTaskID  string // ID of task being edited
-	viewAsTable    bool   // true for table view, false for list view
+	store            *TaskStore
+	tasks            []Task
+	cursor           int
+	viewMode         ViewMode
+	textInput        textinput.Model
+	categoryInput    textinput.Model
+	filterStatus     *TaskStatus
+	filterCategory   *TaskCategory
+	filterActionable bool
+	filterDue        dueWindow
+	filterStalled    bool
+	filterRecent     bool // updated within recentWindow
+	hideFuture       bool // hide tasks whose start date is ahead
+	futureHidden     int  // tasks hidden by hideFuture
+	message          string
+	quitting         bool
+	activeInput      int    // 0 for description, 1 for category
+	editingTaskID    string // ID of task being edited
+	viewAsTable      bool   // true for table view, false for list view
+	filterHistory    []filterState
+	config           Config
+	lang             string          // UI language, see detectLanguage
+	warningHidden    bool            // large-store warning dismissed for this session
+	dueSoon          []Task          // unfinished tasks due within the look-ahead window
+	dueSoonHidden    bool            // due-soon banner dismissed for this session
+	reminders        []Task          // unfinished tasks whose reminder time has passed
+	remindersHidden  bool            // reminder banner dismissed for this session
+	showWelcome      bool            // first-run onboarding panel is visible
+	groupByStatus    bool            // render tasks under status headers
+	showIDs          bool            // append each task's full ID to its row
+	discarded        bool            // quit without saving pending changes
+	saving           bool            // a background save is running
+	saveScheduled    bool            // a save is waiting for the debounce window to end
+	saveStatus       string          // "Saving…" or "Saved", shown in the header
+	marked           map[string]bool // task IDs selected for bulk operations
+	tagTargets       []string        // task IDs the tag prompt applies to
+	tagRemove        bool            // tag prompt removes instead of adds
+	linkRemove       bool            // link prompt removes instead of adds
+	categoryPicker   picker          // category choices while in ModeCategoryPicker
+	pickerReturnMode ViewMode        // create or edit mode the picker was opened from
+	confirm          *confirmation   // pending action in ModeConfirm
+	confirmSeq       int             // numbers confirmations for their timeouts
+	width            int             // terminal width, 0 until known
+	height           int             // terminal height, 0 until known
+	builder          filterState     // draft filters in ModeFilterBuilder
+	builderField     int             // field selected in the filter builder
+	searchQuery      string          // active search text, empty when not searching
+	searchAll        bool            // search notes and category too
+	duplicateWarned  string          // normalized task the user was warned is a duplicate
+	sortKey          string          // one of sortKeys
+	sortReverse      bool
+	groupOrder       []TaskStatus // order of status groups, from config
+	rng              *rand.Rand   // picks the task for R
+	localDir         string       // project .patodo directory, if any; P switches to it
+	badStatusIcons   bool         // status_icons is invalid, so the default icons are shown
+	output           io.Writer    // the program's output, where the completion bell goes
 }
 
 // initialModel creates the initial model
-func initialModel(store *TaskStore) model {
+func initialModel(store *TaskStore, cfg Config) model {
+	lang := detectLanguage(cfg.Language)
+
 	ti := textinput.New()
-	ti.Placeholder = "Enter task description..."
+	ti.Placeholder = translate(lang, "Enter task description...")
 	ti.Focus()
 	ti.CharLimit = 256
 	ti.Width = 50
 
 	ci := textinput.New()
-	ci.Placeholder = "Enter category (work, personal, etc.)..."
+	ci.Placeholder = translate(lang, "Enter category (work, personal, etc.)...")
 	ci.CharLimit = 50
 	ci.Width = 50
 
-	return model{
+	m := model{
 		store:         store,
 		tasks:         store.GetAll(),
 		cursor:        0,
@@ -71,16 +301,130 @@ func initialModel(store *TaskStore) model {
 		categoryInput: ci,
 		activeInput:   0,
 		viewAsTable:   true,
+		config:        cfg,
+		lang:          lang,
+		showWelcome:   store.IsFirstRun() && !cfg.OnboardingDone,
+		searchAll:     cfg.SearchAllFields,
+		hideFuture:    cfg.HideFutureTasks,
+		sortKey:       cfg.DefaultSort,
+		sortReverse:   cfg.DefaultSortReverse,
+		groupOrder:    statusGroupOrder,
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	m.localDir, _ = localTasksDir()
+	if !slices.Contains(sortKeys, m.sortKey) {
+		m.message = m.t("Unknown default_sort %q, using insertion order", m.sortKey)
+		m.sortKey = ""
+	}
+	if len(cfg.GroupOrder) > 0 {
+		if err := validateGroupOrder(cfg.GroupOrder); err != nil {
+			m.message = m.t("Invalid group_order (%v), using the default order", err)
+		} else {
+			m.groupOrder = cfg.GroupOrder
+		}
+	}
+	if err := validateStatusIcons(cfg.StatusIcons); err != nil {
+		// m.config is saved back to config.json, so the setting is kept
+		// there for the user to fix
+		m.message = m.t("Invalid status_icons (%v), using the default icons", err)
+		m.badStatusIcons = true
 	}
+	if warning := m.loadWarning(); warning != "" {
+		m.message = warning
+	}
+	m.refreshTasks()
+	m.cursor = m.indexOfTask(cfg.LastTaskID)
+	return m
 }
 
 func (m model) Init() tea.Cmd {
 	return textinput.Blink
 }
 
+// saveTickMsg ends the save debounce window; see Config.SaveDebounceMs
+type saveTickMsg struct{}
+
+// tasksSavedMsg reports that a background save has finished
+type tasksSavedMsg struct {
+	err error
+}
+
+// saveTasks writes the store's deferred changes off the update loop
+func saveTasks(store *TaskStore) tea.Cmd {
+	return func() tea.Msg {
+		return tasksSavedMsg{err: store.Flush()}
+	}
+}
+
+// Update handles a message, then starts a background save if it left
+// unsaved changes. Only one save runs at a time; changes made while it
+// runs are picked up by the next one, so saves land in order. With a
+// debounce, the save waits for the window to end and takes every change
+// made meanwhile; quitting flushes whatever is still waiting.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	m = updated.(model)
+
+	// After a failed save, wait for the next change or key to retry
+	// rather than spinning
+	if saved, ok := msg.(tasksSavedMsg); ok && saved.err != nil {
+		return m, cmd
+	}
+	if m.saving || m.saveScheduled || m.quitting || !m.store.Unsaved() {
+		return m, cmd
+	}
+	if _, windowOver := msg.(saveTickMsg); !windowOver && m.config.SaveDebounceMs > 0 {
+		m.saveScheduled = true
+		tick := tea.Tick(time.Duration(m.config.SaveDebounceMs)*time.Millisecond, func(time.Time) tea.Msg {
+			return saveTickMsg{}
+		})
+		return m, tea.Batch(cmd, tick)
+	}
+	m.saving = true
+	m.saveStatus = m.t("Saving…")
+	return m, tea.Batch(cmd, saveTasks(m.store))
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case saveTickMsg:
+		// Update starts the save now that the window is over
+		m.saveScheduled = false
+		return m, nil
+
+	case tasksSavedMsg:
+		m.saving = false
+		m.saveStatus = m.t("Saved")
+		if msg.err != nil {
+			m.saveStatus = ""
+			m.message = m.t("Error saving tasks: %v", msg.err)
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case linkOpenedMsg:
+		if msg.err != nil {
+			m.message = m.t("Error opening link: %v", msg.err)
+		}
+		return m, nil
+
+	case confirmTimeoutMsg:
+		if m.viewMode == ModeConfirm && m.confirm != nil && m.confirm.seq == msg.seq {
+			m.confirm = nil
+			m.viewMode = ModeList
+			m.message = m.t("Cancelled")
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.showWelcome {
+			return m.dismissWelcome(), nil
+		}
+
 		switch m.viewMode {
 		case ModeCreate:
 			return m.updateCreateMode(msg)
@@ -90,6 +434,34 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateFilterMode(msg)
 		case ModeFilterCategory:
 			return m.updateFilterCategoryMode(msg)
+		case ModeRename:
+			return m.updateRenameMode(msg)
+		case ModeBlockReason:
+			return m.updateBlockReasonMode(msg)
+		case ModeConfirm:
+			return m.updateConfirmMode(msg)
+		case ModeFilterBuilder:
+			return m.updateFilterBuilderMode(msg)
+		case ModeSearch:
+			return m.updateSearchMode(msg)
+		case ModeNotes:
+			return m.updateNotesMode(msg)
+		case ModeDetail:
+			return m.updateDetailMode(msg)
+		case ModeTag:
+			return m.updateTagMode(msg)
+		case ModeCategoryPicker:
+			return m.updateCategoryPickerMode(msg)
+		case ModeLink:
+			return m.updateLinkMode(msg)
+		case ModeTypeAhead:
+			return m.updateTypeAheadMode(msg)
+		case ModeReminder:
+			return m.updateReminderMode(msg)
+		case ModeStartDate:
+			return m.updateStartDateMode(msg)
+		case ModeCommand:
+			return m.updateCommandMode(msg)
 		default:
 			return m.updateListMode(msg)
 		}
@@ -100,21 +472,219 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// dismissWelcome hides the onboarding panel and remembers not to show it again
+func (m model) dismissWelcome() model {
+	m.showWelcome = false
+	m.config.OnboardingDone = true
+	if err := m.config.Save(); err != nil {
+		m.message = m.t("Error saving config: %v", err)
+	}
+	return m
+}
+
 func (m model) updateListMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q":
 		m.quitting = true
+		m.saveLastTask()
+		return m, tea.Quit
+
+	case "ctrl+x":
+		// Quit without flushing anything. Saves run in the background, so
+		// this only skips changes that haven't been written yet.
+		m.quitting = true
+		m.discarded = true
 		return m, tea.Quit
 
+	case "ctrl+r":
+		// Reload from disk, keeping the cursor on the same task if it
+		// still exists
+		currentID := ""
+		if m.hasCurrentTask() {
+			currentID = m.getCurrentTask().ID
+		}
+		// Write changes still waiting for a save first, or Load would
+		// drop them
+		if err := m.store.Flush(); err != nil {
+			m.message = m.t("Error saving tasks: %v", err)
+			break
+		}
+		if err := m.store.Load(); err != nil {
+			m.message = m.t("Error reloading tasks: %v", err)
+			break
+		}
+		m.refreshTasks()
+		if m.cursor >= len(m.tasks) {
+			m.cursor = max(len(m.tasks)-1, 0)
+		}
+		for i, task := range m.tasks {
+			if task.ID == currentID {
+				m.cursor = i
+				break
+			}
+		}
+		m.message = m.t("Reloaded %d tasks", len(m.store.GetAll()))
+		if warning := m.loadWarning(); warning != "" {
+			m.message = warning
+		}
+
+	case "esc":
+		m.message = ""
+		return m, nil
+
+	case "?":
+		m.config.ShowHelp = !m.config.ShowHelp
+		if err := m.config.Save(); err != nil {
+			m.message = m.t("Error saving config: %v", err)
+		}
+		return m, nil
+
+	case ">", "W":
+		if !m.hasCurrentTask() {
+			break
+		}
+		bump := 24 * time.Hour
+		if msg.String() == "W" {
+			bump = 7 * 24 * time.Hour
+		}
+		task := m.getCurrentTask()
+		due, err := m.store.BumpDueDate(task.ID, bump)
+		if err != nil {
+			m.message = m.t("Error updating due date: %v", err)
+			break
+		}
+		m.message = m.t("Due %s", due.Format("Mon 2006-01-02"))
+		m.refreshTasks()
+		m.cursor = m.indexOfTask(task.ID)
+		return m, nil
+
+	case "R":
+		if i := pickRandomPending(m.tasks, m.rng); i != -1 {
+			m.cursor = i
+			m.message = m.t("Picked: %s", m.tasks[i].Description)
+		} else {
+			m.message = m.t("No pending tasks to pick from")
+		}
+		return m, nil
+
+	case "D":
+		m.message = m.t("Opening %s", m.store.Dir())
+		return m, openLink(m.store.Dir())
+
+	case "P":
+		m.switchStore()
+		return m, nil
+
+	case ":":
+		m.viewMode = ModeCommand
+		m.textInput.Reset()
+		m.textInput.Focus()
+		m.activeInput = 0
+		m.message = m.t("Command: filter, sort or new (Enter to run, ESC to cancel)")
+		return m, textinput.Blink
+
+	case "'":
+		m.viewMode = ModeTypeAhead
+		m.message = m.t("Type a letter to jump to the next task starting with it (ESC to stop)")
+		return m, nil
+
+	case "c":
+		m.config.ShowCategories = !m.config.ShowCategories
+		m.message = m.t("Categories shown")
+		if !m.config.ShowCategories {
+			m.message = m.t("Categories hidden")
+		}
+		if err := m.config.Save(); err != nil {
+			m.message = m.t("Error saving config: %v", err)
+		}
+		return m, nil
+
+	case "z":
+		m.config.WrapRows = !m.config.WrapRows
+		m.message = m.t("Long rows wrapped")
+		if !m.config.WrapRows {
+			m.message = m.t("Long rows truncated")
+		}
+		if err := m.config.Save(); err != nil {
+			m.message = m.t("Error saving config: %v", err)
+		}
+		return m, nil
+
+	case "C":
+		if !m.hasCurrentTask() {
+			break
+		}
+		category := m.getCurrentTask().Category
+		pending := 0
+		for _, task := range m.store.Filter(FilterOptions{Category: &category}) {
+			if task.Status != StatusDone {
+				pending++
+			}
+		}
+		if pending == 0 {
+			m.message = m.t("No unfinished tasks in %s", m.categoryLabel(category))
+			break
+		}
+		// Always confirm: this can touch tasks hidden by the current filter
+		cmd := m.askConfirm(m.t("Mark %d tasks in %s as done?", pending, m.categoryLabel(category)), func(m *model) {
+			count, err := m.store.CompleteCategory(category)
+			if err != nil {
+				m.message = m.t("Error completing category: %v", err)
+			} else {
+				m.message = m.t("Marked %d tasks in %s as done", count, m.categoryLabel(category))
+			}
+			m.refreshTasks()
+		})
+		return m, cmd
+
+	case "/":
+		m.viewMode = ModeSearch
+		m.textInput.Reset()
+		m.textInput.SetValue(m.searchQuery)
+		m.textInput.Focus()
+		m.activeInput = 0
+		m.message = m.searchHint()
+		return m, textinput.Blink
+
+	case "o":
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			m.viewMode = ModeNotes
+			m.editingTaskID = task.ID
+			m.textInput.Reset()
+			m.textInput.SetValue(task.Notes)
+			m.textInput.Focus()
+			m.activeInput = 0
+			m.message = m.t("Edit notes (Enter to save, ESC to cancel)")
+			return m, textinput.Blink
+		}
+
+	case "enter":
+		if m.hasCurrentTask() {
+			m.viewMode = ModeDetail
+			m.message = ""
+		}
+
+	case "}":
+		m.jumpToStatus(1)
+
+	case "{":
+		m.jumpToStatus(-1)
+
 	case "n":
 		m.viewMode = ModeCreate
 		m.textInput.Reset()
 		m.categoryInput.Reset()
+		if m.filterCategory != nil {
+			// New tasks most likely belong to the category being viewed
+			m.categoryInput.SetValue(string(*m.filterCategory))
+		}
 		m.textInput.Focus()
 		m.categoryInput.Blur()
 		m.activeInput = 0
 		m.editingTaskID = ""
-		m.message = "Enter task details (Tab to switch fields, Enter to save, ESC to cancel)"
+		m.duplicateWarned = ""
+		m.message = m.t("Enter task details (Tab to switch fields, Ctrl+P to pick a category, Enter to save, ESC to cancel)")
 		return m, textinput.Blink
 
 	case "e":
@@ -127,115 +697,400 @@ func (m model) updateListMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.textInput.Focus()
 			m.categoryInput.Blur()
 			m.activeInput = 0
-			m.message = "Edit task (Tab to switch fields, Enter to save, ESC to cancel)"
+			m.message = m.t("Edit task (Tab to switch fields, Ctrl+P to pick a category, Enter to save, ESC to cancel)")
+			return m, textinput.Blink
+		}
+
+	case "r":
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			m.viewMode = ModeRename
+			m.editingTaskID = task.ID
+			m.textInput.SetValue(task.Description)
+			m.textInput.Focus()
+			m.activeInput = 0
+			m.message = m.t("Rename task (Enter to save, ESC to cancel)")
 			return m, textinput.Blink
 		}
 
+	case "w":
+		if m.showTaskCountWarning() || m.showDueSoon() || m.showReminders() {
+			m.warningHidden = true
+			m.dueSoonHidden = true
+			m.remindersHidden = true
+			m.message = m.t("Warning dismissed for this session")
+		}
+		return m, nil
+
+	case "backspace":
+		if m.popFilterHistory() {
+			m.message = m.t("Restored previous filter (%s)", m.filterInfo())
+		} else {
+			m.message = m.t("No previous filter to restore")
+		}
+		return m, nil
+
 	case "f":
 		m.viewMode = ModeFilter
-		m.message = "Filter: (a)ll, (p)ending, (i)n-progress, (d)one, (c)ategory, ESC to cancel"
+		m.message = m.t("Filter: (a)ll, (p)ending, (i)n-progress, (d)one, (t)oday/actionable, (s)talled, (u)pdated this week, (c)ategory, (b)uilder, ESC to cancel")
 		return m, nil
 
 	case "v":
 		m.viewAsTable = !m.viewAsTable
 		if m.viewAsTable {
-			m.message = "Switched to table view"
+			m.message = m.t("Switched to table view")
 		} else {
-			m.message = "Switched to list view"
+			m.message = m.t("Switched to list view")
 		}
 		return m, nil
 
-	case "up", "k":
-		if m.cursor > 0 {
-			m.cursor--
-		}
-
-	case "down", "j":
-		if m.cursor < len(m.tasks)-1 {
-			m.cursor++
-		}
-
-	case "d":
+	case "*":
 		if m.hasCurrentTask() {
 			task := m.getCurrentTask()
-			if task.Status == StatusDone {
-				m.updateTaskStatus(StatusPending)
-				m.message = "Task marked as pending"
+			if err := m.store.TogglePin(task.ID); err != nil {
+				m.message = m.t("Error pinning task: %v", err)
+			} else if task.Pinned {
+				m.message = m.t("Task unpinned")
 			} else {
-				m.updateTaskStatus(StatusDone)
-				m.message = "Task marked as done!"
+				m.message = m.t("Task pinned")
 			}
+			m.refreshTasks()
+			m.cursor = m.indexOfTask(task.ID)
 		}
 
-	case "i":
-		if m.hasCurrentTask() {
-			m.updateTaskStatus(StatusInProgress)
-			m.message = "Task marked as in-progress"
+	case "I":
+		m.showIDs = !m.showIDs
+		if m.showIDs {
+			m.message = m.t("Showing task IDs")
+		} else {
+			m.message = m.t("Hiding task IDs")
 		}
+		return m, nil
 
-	case "p":
+	case "B":
 		if m.hasCurrentTask() {
-			m.updateTaskStatus(StatusPending)
-			m.message = "Task marked as pending"
+			task := m.getCurrentTask()
+			if err := m.store.Bury(task.ID); err != nil {
+				m.message = m.t("Error updating task: %v", err)
+			} else {
+				m.message = m.t("Buried: %s", task.Description)
+			}
+			m.refreshTasks()
+			m.cursor = m.indexOfTask(task.ID)
 		}
 
-	case "x":
+	case "N":
 		if m.hasCurrentTask() {
 			task := m.getCurrentTask()
-			if err := m.store.Delete(task.ID); err != nil {
-				m.message = fmt.Sprintf("Error deleting task: %v", err)
+			id := task.ID
+			if task.NextAction {
+				id = ""
+			}
+			if err := m.store.SetNextAction(id); err != nil {
+				m.message = m.t("Error updating task: %v", err)
+			} else if task.NextAction {
+				m.message = m.t("Next action cleared")
 			} else {
-				m.message = "Task deleted"
+				m.message = m.t("Next action: %s", task.Description)
 			}
 			m.refreshTasks()
-			if m.cursor >= len(m.tasks) && m.cursor > 0 {
-				m.cursor--
-			}
+			m.cursor = m.indexOfTask(task.ID)
 		}
-	}
 
-	return m, nil
-}
+	case "S":
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			m.viewMode = ModeStartDate
+			m.editingTaskID = task.ID
+			m.textInput.Reset()
+			if task.StartDate != nil {
+				m.textInput.SetValue(task.StartDate.Format(time.DateOnly))
+			}
+			m.textInput.Focus()
+			m.activeInput = 0
+			m.message = m.t("Start on? (2006-01-02 or 3d; empty clears)")
+			return m, textinput.Blink
+		}
 
-func (m model) updateCreateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyEsc:
-		m.viewMode = ModeList
-		m.message = "Task creation cancelled"
+	case "H":
+		m.hideFuture = !m.hideFuture
+		m.refreshTasks()
+		if m.hideFuture {
+			m.message = m.t("Hiding tasks that haven't started")
+		} else {
+			m.message = m.t("Showing tasks that haven't started")
+		}
+		if m.cursor >= len(m.tasks) {
+			m.cursor = max(len(m.tasks)-1, 0)
+		}
 		return m, nil
 
-	case tea.KeyTab:
-		// Switch between description and category inputs
-		if m.activeInput == 0 {
-			m.activeInput = 1
-			m.textInput.Blur()
-			m.categoryInput.Focus()
-		} else {
-			m.activeInput = 0
-			m.categoryInput.Blur()
+	case "M":
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			m.viewMode = ModeReminder
+			m.editingTaskID = task.ID
+			m.textInput.Reset()
+			if task.RemindAt != nil {
+				m.textInput.SetValue(task.RemindAt.Format("2006-01-02 15:04"))
+			}
 			m.textInput.Focus()
+			m.activeInput = 0
+			m.message = m.t("Remind at? (2006-01-02 15:04, 2006-01-02 or 2h; empty clears)")
+			return m, textinput.Blink
 		}
-		return m, textinput.Blink
 
-	case tea.KeyEnter:
-		description := strings.TrimSpace(m.textInput.Value())
-		if description == "" {
-			m.viewMode = ModeList
-			m.message = "Task creation cancelled - description is required"
-			return m, nil
-		}
+	case "b":
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			if task.Blocked {
+				if err := m.store.SetBlocked(task.ID, false, ""); err != nil {
+					m.message = m.t("Error updating task: %v", err)
+				} else {
+					m.message = m.t("Task unblocked")
+				}
+				m.refreshTasks()
+				m.cursor = m.indexOfTask(task.ID)
+				return m, nil
+			}
 
-		categoryStr := strings.TrimSpace(m.categoryInput.Value())
-		if categoryStr == "" {
-			m.viewMode = ModeList
-			m.message = "Task creation cancelled - category is required"
-			return m, nil
+			m.viewMode = ModeBlockReason
+			m.editingTaskID = task.ID
+			m.textInput.Reset()
+			m.textInput.Focus()
+			m.activeInput = 0
+			m.message = m.t("Blocked by? (optional, Enter to save, ESC to cancel)")
+			return m, textinput.Blink
 		}
-		category := TaskCategory(categoryStr)
+
+	case "[":
+		m.cycleCategory(-1)
+
+	case "]":
+		m.cycleCategory(1)
+
+	case "-":
+		m.clearCategory()
+
+	case "0", "1", "2", "3":
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			priority := Priority(msg.String()[0] - '0')
+			if err := m.store.SetPriority(task.ID, priority); err != nil {
+				m.message = m.t("Error updating priority: %v", err)
+				break
+			}
+			m.message = m.t("Priority: %s", m.t(priority.String()))
+			m.refreshTasks()
+			m.cursor = m.indexOfTask(task.ID)
+		}
+
+	case "+", "_":
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			priority := task.Priority + 1
+			if msg.String() == "_" {
+				priority = task.Priority - 1
+			}
+			priority = min(max(priority, PriorityNone), PriorityHigh)
+			if priority == task.Priority {
+				m.message = m.t("Priority is already %s", m.t(priority.String()))
+				break
+			}
+			if err := m.store.SetPriority(task.ID, priority); err != nil {
+				m.message = m.t("Error updating priority: %v", err)
+				break
+			}
+			m.message = m.t("Priority: %s", m.t(priority.String()))
+			m.refreshTasks()
+			m.cursor = m.indexOfTask(task.ID)
+		}
+
+	case "t":
+		m.cycleStatusFilter()
+
+	case "g":
+		m.groupByStatus = !m.groupByStatus
+		m.refreshTasks()
+		m.cursor = 0
+		if m.groupByStatus {
+			m.message = m.t("Grouped by status")
+		} else {
+			m.message = m.t("Grouping off")
+		}
+		return m, nil
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.tasks)-1 {
+			m.cursor++
+		}
+
+	case "d":
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			if task.Status == StatusDone {
+				m.updateTaskStatus(StatusPending)
+				m.message = m.t("Task marked as pending")
+			} else {
+				cmd := m.updateTaskStatus(StatusDone)
+				m.message = m.t("Task marked as done!")
+				return m, cmd
+			}
+		}
+
+	case "F":
+		// D already opens the data folder, so "finish" gets F
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			if task.Status == StatusDone {
+				m.message = m.t("Task is already done")
+				break
+			}
+			order := make([]string, len(m.tasks))
+			for i, t := range m.tasks {
+				order[i] = t.ID
+			}
+			cmd := m.updateTaskStatus(StatusDone)
+			if next, ok := m.nextIncomplete(order, m.cursor); ok {
+				m.cursor = next
+				m.message = m.t("Done: %s", task.Description)
+			} else {
+				m.cursor = m.indexOfTask(task.ID)
+				m.message = m.t("All done!")
+			}
+			return m, cmd
+		}
+
+	case "i":
+		if m.hasCurrentTask() {
+			m.updateTaskStatus(StatusInProgress)
+			m.message = m.t("Task marked as in-progress")
+		}
+
+	case "p":
+		if m.hasCurrentTask() {
+			m.updateTaskStatus(StatusPending)
+			m.message = m.t("Task marked as pending")
+		}
+
+	case " ":
+		if m.hasCurrentTask() {
+			task := m.getCurrentTask()
+			if m.marked[task.ID] {
+				delete(m.marked, task.ID)
+			} else {
+				if m.marked == nil {
+					m.marked = make(map[string]bool)
+				}
+				m.marked[task.ID] = true
+			}
+			m.message = m.t("%d tasks selected", len(m.marked))
+			if m.cursor < len(m.tasks)-1 {
+				m.cursor++
+			}
+		}
+
+	case "a", "A":
+		ids := m.targetTaskIDs()
+		if len(ids) == 0 {
+			break
+		}
+		m.tagTargets = ids
+		m.tagRemove = msg.String() == "A"
+		m.viewMode = ModeTag
+		m.textInput.Reset()
+		m.textInput.Focus()
+		m.message = m.t("Tag to add to %d task(s)? (Enter to apply, ESC to cancel)", len(ids))
+		if m.tagRemove {
+			m.message = m.t("Tag to remove from %d task(s)? (Enter to apply, ESC to cancel)", len(ids))
+		}
+		return m, textinput.Blink
+
+	case "x":
+		ids := m.targetTaskIDs()
+		if len(ids) == 0 {
+			break
+		}
+		prompt := m.t("Delete this task?")
+		if len(ids) > 1 {
+			prompt = m.t("Delete %d tasks?", len(ids))
+		}
+		cmd := m.confirmOrRun(len(ids), prompt, func(m *model) {
+			count, err := m.store.DeleteBatch(ids)
+			if err != nil {
+				m.message = m.t("Error deleting tasks: %v", err)
+			} else if count == 1 {
+				m.message = m.t("Task deleted")
+			} else {
+				m.message = m.t("%d tasks deleted", count)
+			}
+			m.marked = nil
+			m.refreshTasks()
+			if m.cursor >= len(m.tasks) && m.cursor > 0 {
+				m.cursor = len(m.tasks) - 1
+			}
+		})
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m model) updateCreateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.viewMode = ModeList
+		m.message = m.t("Task creation cancelled")
+		return m, nil
+
+	case tea.KeyTab:
+		// Switch between description and category inputs
+		if m.activeInput == 0 {
+			m.activeInput = 1
+			m.textInput.Blur()
+			m.categoryInput.Focus()
+		} else {
+			m.activeInput = 0
+			m.categoryInput.Blur()
+			m.textInput.Focus()
+		}
+		return m, textinput.Blink
+
+	case tea.KeyCtrlP:
+		return m.openCategoryPicker()
+
+	case tea.KeyCtrlS:
+		return m.swapInputs(), nil
+
+	case tea.KeyEnter:
+		description := strings.TrimSpace(m.textInput.Value())
+		categoryStr := strings.TrimSpace(m.categoryInput.Value())
+		category := TaskCategory(categoryStr)
+		if err := validateTask(description, category, true); err != nil {
+			m.viewMode = ModeList
+			m.message = m.t("Task creation cancelled - %v", err)
+			return m, nil
+		}
+
+		// Warn once about a duplicate; pressing Enter again creates it anyway
+		key := string(category) + "\x00" + normalizeDescription(description)
+		if !m.config.SkipDuplicateCheck && m.duplicateWarned != key {
+			if dup := m.store.findDuplicate(description, category); dup != nil {
+				m.duplicateWarned = key
+				m.message = m.t("Similar task exists: %s [%s] - press Enter again to create anyway", dup.Description, dup.Category)
+				return m, nil
+			}
+		}
+		m.duplicateWarned = ""
 		if err := m.store.Add(description, category); err != nil {
-			m.message = fmt.Sprintf("Error creating task: %v", err)
+			m.message = m.t("Error creating task: %v", err)
 		} else {
-			m.message = fmt.Sprintf("Task created: %s [%s]", description, categoryStr)
+			m.message = m.t("Task created: %s [%s]", description, categoryStr)
 		}
 		m.refreshTasks()
 		m.viewMode = ModeList
@@ -245,6 +1100,9 @@ func (m model) updateCreateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	if m.activeInput == 0 {
 		m.textInput, cmd = m.textInput.Update(msg)
+		if msg.String() == " " {
+			m.expandDescriptionSnippet()
+		}
 	} else {
 		m.categoryInput, cmd = m.categoryInput.Update(msg)
 	}
@@ -255,7 +1113,7 @@ func (m model) updateEditMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEsc:
 		m.viewMode = ModeList
-		m.message = "Edit cancelled"
+		m.message = m.t("Edit cancelled")
 		m.editingTaskID = ""
 		return m, nil
 
@@ -272,21 +1130,37 @@ func (m model) updateEditMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, textinput.Blink
 
+	case tea.KeyCtrlP:
+		return m.openCategoryPicker()
+
+	case tea.KeyCtrlS:
+		return m.swapInputs(), nil
+
 	case tea.KeyEnter:
 		description := strings.TrimSpace(m.textInput.Value())
-		if description == "" {
+		category := TaskCategory(strings.TrimSpace(m.categoryInput.Value()))
+		if err := validateTask(description, category, false); err != nil {
 			m.viewMode = ModeList
-			m.message = "Edit cancelled - description is required"
+			m.message = m.t("Edit cancelled - %v", err)
 			m.editingTaskID = ""
 			return m, nil
 		}
 
-		categoryStr := strings.TrimSpace(m.categoryInput.Value())
-		category := TaskCategory(categoryStr)
-		if err := m.store.Update(m.editingTaskID, description, category); err != nil {
-			m.message = fmt.Sprintf("Error updating task: %v", err)
-		} else {
-			m.message = "Task updated successfully"
+		started := false
+		err := m.store.Batch(func(b *TaskBatch) error {
+			b.Update(m.editingTaskID, description, category)
+			if task, ok := b.Get(m.editingTaskID); ok && m.config.StartOnEdit && task.Status == StatusPending {
+				started = b.UpdateStatus(m.editingTaskID, StatusInProgress)
+			}
+			return nil
+		})
+		switch {
+		case err != nil:
+			m.message = m.t("Error updating task: %v", err)
+		case started:
+			m.message = m.t("Task updated and marked in progress")
+		default:
+			m.message = m.t("Task updated successfully")
 		}
 		m.refreshTasks()
 		m.editingTaskID = ""
@@ -297,119 +1171,1341 @@ func (m model) updateEditMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	if m.activeInput == 0 {
 		m.textInput, cmd = m.textInput.Update(msg)
+		if msg.String() == " " {
+			m.expandDescriptionSnippet()
+		}
 	} else {
 		m.categoryInput, cmd = m.categoryInput.Update(msg)
 	}
 	return m, cmd
 }
 
-func (m model) updateFilterMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
+// swapInputs swaps the contents of the description and category inputs,
+// for when they were typed into the wrong fields. Focus stays where it is.
+func (m model) swapInputs() model {
+	description, category := m.textInput.Value(), m.categoryInput.Value()
+	m.textInput.SetValue(category)
+	m.categoryInput.SetValue(description)
+	return m
+}
+
+// expandDescriptionSnippet replaces a snippet trigger just typed in the
+// description input with its expansion
+func (m *model) expandDescriptionSnippet() {
+	value, pos, ok := expandSnippet(m.textInput.Value(), m.textInput.Position(), m.config.Snippets)
+	if ok {
+		m.textInput.SetValue(value)
+		m.textInput.SetCursor(pos)
+	}
+}
+
+// expandSnippet expands the word before the space at pos if it's one of
+// the snippet triggers. The trigger has to be a whole word, so it's only
+// replaced at the start of the text or after whitespace. It returns the
+// new text and cursor position, and whether anything was expanded.
+func expandSnippet(value string, pos int, snippets map[string]string) (string, int, bool) {
+	runes := []rune(value)
+	if pos < 1 || pos > len(runes) || runes[pos-1] != ' ' {
+		return value, pos, false
+	}
+
+	start := pos - 1
+	for start > 0 && !unicode.IsSpace(runes[start-1]) {
+		start--
+	}
+	expansion, ok := snippets[string(runes[start:pos-1])]
+	if !ok || start == pos-1 {
+		return value, pos, false
+	}
+
+	expanded := string(runes[:start]) + expansion + string(runes[pos-1:])
+	return expanded, start + len([]rune(expansion)) + 1, true
+}
+
+// openCategoryPicker lists the existing categories to choose from in
+// create or edit mode
+func (m model) openCategoryPicker() (tea.Model, tea.Cmd) {
+	options := append(m.store.GetCategories(), m.t(pickerNew))
+	m.categoryPicker = newPicker(m.t("Pick a category:"), options, strings.TrimSpace(m.categoryInput.Value()))
+	m.pickerReturnMode = m.viewMode
+	m.viewMode = ModeCategoryPicker
+	m.message = m.t("↑/↓ choose, Enter to select, ESC to go back")
+	return m, nil
+}
+
+func (m model) updateCategoryPickerMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var result pickerResult
+	m.categoryPicker, result = m.categoryPicker.Update(msg)
+	if result == pickerPending {
+		return m, nil
+	}
+
+	m.viewMode = m.pickerReturnMode
+	if result == pickerChosen {
+		if choice := m.categoryPicker.Selected(); choice == m.t(pickerNew) {
+			m.categoryInput.Reset()
+			m.message = m.t("Type the new category (Enter to save, ESC to cancel)")
+		} else {
+			m.categoryInput.SetValue(choice)
+			m.message = m.t("Category: %s (Enter to save, ESC to cancel)", choice)
+		}
+	} else {
+		m.message = m.t("Category unchanged")
+	}
+
+	// Continue in the category field
+	m.activeInput = 1
+	m.textInput.Blur()
+	m.categoryInput.Focus()
+	return m, textinput.Blink
+}
+
+func (m model) updateRenameMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
 		m.viewMode = ModeList
-		m.message = "Filter cancelled"
+		m.message = m.t("Rename cancelled")
+		m.editingTaskID = ""
 		return m, nil
 
-	case "a":
-		m.filterStatus = nil
-		m.filterCategory = nil
+	case tea.KeyEnter:
+		description := strings.TrimSpace(m.textInput.Value())
+		if err := validateTask(description, "", false); err != nil {
+			m.viewMode = ModeList
+			m.message = m.t("Rename cancelled - %v", err)
+			m.editingTaskID = ""
+			return m, nil
+		}
+
+		if err := m.store.UpdateDescription(m.editingTaskID, description); err != nil {
+			m.message = m.t("Error renaming task: %v", err)
+		} else {
+			m.message = m.t("Task renamed: %s", description)
+		}
 		m.refreshTasks()
+		m.editingTaskID = ""
 		m.viewMode = ModeList
-		m.message = "Showing all tasks"
-		m.cursor = 0
-
-	case "p":
-		m.applyStatusFilter(StatusPending, "Showing pending tasks")
+		return m, nil
+	}
 
-	case "i":
-		m.applyStatusFilter(StatusInProgress, "Showing in-progress tasks")
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
 
-	case "d":
-		m.applyStatusFilter(StatusDone, "Showing done tasks")
+func (m model) updateBlockReasonMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.viewMode = ModeList
+		m.message = m.t("Block cancelled")
+		m.editingTaskID = ""
+		return m, nil
 
-	case "c":
-		m.viewMode = ModeFilterCategory
-		m.message = "Select category to filter by"
+	case tea.KeyEnter:
+		reason := strings.TrimSpace(m.textInput.Value())
+		if err := m.store.SetBlocked(m.editingTaskID, true, reason); err != nil {
+			m.message = m.t("Error updating task: %v", err)
+		} else {
+			m.message = m.t("Task marked as blocked")
+		}
+		m.refreshTasks()
+		m.cursor = m.indexOfTask(m.editingTaskID)
+		m.editingTaskID = ""
+		m.viewMode = ModeList
+		return m, nil
 	}
 
-	return m, nil
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
 }
 
-func (m model) updateFilterCategoryMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
+func (m model) updateReminderMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
 		m.viewMode = ModeList
-		m.message = "Filter cancelled"
+		m.message = m.t("Reminder cancelled")
+		m.editingTaskID = ""
 		return m, nil
 
-	case "a":
-		m.filterCategory = nil
+	case tea.KeyEnter:
+		at, err := parseReminder(m.textInput.Value(), time.Now())
+		if err != nil {
+			m.message = m.t("Invalid reminder time: %s", strings.TrimSpace(m.textInput.Value()))
+			return m, nil
+		}
+		if err := m.store.SetReminder(m.editingTaskID, at); err != nil {
+			m.message = m.t("Error updating task: %v", err)
+		} else if at == nil {
+			m.message = m.t("Reminder cleared")
+		} else {
+			m.message = m.t("Reminder set for %s", at.Format("Mon 2006-01-02 15:04"))
+		}
 		m.refreshTasks()
+		m.cursor = m.indexOfTask(m.editingTaskID)
+		m.editingTaskID = ""
 		m.viewMode = ModeList
-		m.message = "Showing all categories"
-		m.cursor = 0
 		return m, nil
 	}
 
-	// Check if user pressed a number key for category selection
-	categories := m.store.GetCategories()
-	if len(msg.String()) == 1 && msg.String()[0] >= '1' && msg.String()[0] <= '9' {
-		idx := int(msg.String()[0] - '1')
-		if idx < len(categories) {
-			categoryStr := categories[idx]
-			category := TaskCategory(categoryStr)
-			m.filterCategory = &category
-			m.refreshTasks()
-			m.viewMode = ModeList
-			m.message = fmt.Sprintf("Showing tasks in category: %s", categoryStr)
-			m.cursor = 0
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.viewMode = ModeList
+		m.message = m.t("Command cancelled")
+		return m, nil
+
+	case tea.KeyEnter:
+		// On a mistake stay at the prompt so the command can be fixed
+		cmd, err := parseCommandLine(m.textInput.Value())
+		if err == nil {
+			err = m.execCommand(cmd)
 		}
+		if err != nil {
+			m.message = m.t("Command error: %v", err)
+			return m, nil
+		}
+		m.viewMode = ModeList
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// execCommand carries out a command from the : prompt
+func (m *model) execCommand(cmd command) error {
+	switch cmd.kind {
+	case cmdFilter:
+		m.pushFilterHistory()
+		m.setFilter(filterState{status: cmd.status, category: cmd.category, actionable: cmd.actionable})
+		m.refreshTasks()
+		m.cursor = 0
+		m.message = m.t("Filter: %s", m.filterInfo())
+
+	case cmdSort:
+		m.sortKey = cmd.sortKey
+		m.sortReverse = cmd.reverse
+		m.refreshTasks()
+		m.cursor = 0
+		if cmd.sortKey == "" {
+			m.message = m.t("Sorted in insertion order")
+		} else {
+			m.message = m.t("Sorted by %s", cmd.sortKey)
+		}
+
+	case cmdNew:
+		var category TaskCategory
+		if cmd.category != nil {
+			category = *cmd.category
+		}
+		if err := validateTask(cmd.description, category, true); err != nil {
+			return fmt.Errorf("%w (add @category)", err)
+		}
+		if err := m.store.Add(cmd.description, category); err != nil {
+			return err
+		}
+		m.refreshTasks()
+		m.message = m.t("Task created: %s [%s]", cmd.description, category)
+	}
+	return nil
+}
+
+func (m model) updateStartDateMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.viewMode = ModeList
+		m.message = m.t("Start date cancelled")
+		m.editingTaskID = ""
+		return m, nil
+
+	case tea.KeyEnter:
+		start, err := parseStartDate(m.textInput.Value(), time.Now())
+		if err != nil {
+			m.message = m.t("Invalid start date: %s", strings.TrimSpace(m.textInput.Value()))
+			return m, nil
+		}
+		if err := m.store.SetStartDate(m.editingTaskID, start); err != nil {
+			m.message = m.t("Error updating task: %v", err)
+		} else if start == nil {
+			m.message = m.t("Start date cleared")
+		} else {
+			m.message = m.t("Starts %s", start.Format("Mon 2006-01-02"))
+		}
+		m.refreshTasks()
+		m.cursor = m.indexOfTask(m.editingTaskID)
+		m.editingTaskID = ""
+		m.viewMode = ModeList
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// parseStartDate reads a start date typed in the TUI: a date, or a number
+// of days or weeks from today ("3d", "2w"). The task starts at midnight
+// local time. Empty input clears the start date.
+func parseStartDate(s string, now time.Time) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if start, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return &start, nil
+	}
+	d, err := parseRelativeDuration(s)
+	if err != nil {
+		return nil, err
+	}
+	start := startOfDay(now.Add(d))
+	return &start, nil
+}
+
+// parseReminder reads a reminder time typed in the TUI: a local date and
+// time, a bare date (reminding from midnight, so it shows on the first start
+// that day) or a duration from now. Empty input clears the reminder.
+func parseReminder(s string, now time.Time) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		at := now.Add(d)
+		return &at, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", time.DateOnly} {
+		if at, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &at, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid reminder time: %s", s)
+}
+
+func (m model) updateTagMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.viewMode = ModeList
+		m.message = m.t("Tagging cancelled")
+		m.tagTargets = nil
+		return m, nil
+
+	case tea.KeyEnter:
+		tag := strings.TrimSpace(m.textInput.Value())
+		if tag == "" {
+			m.message = m.t("Tag cannot be empty")
+			return m, nil
+		}
+
+		var count int
+		var err error
+		if m.tagRemove {
+			count, err = m.store.RemoveTagBatch(m.tagTargets, tag)
+		} else {
+			count, err = m.store.AddTagBatch(m.tagTargets, tag)
+		}
+		switch {
+		case err != nil:
+			m.message = m.t("Error updating tags: %v", err)
+		case m.tagRemove:
+			m.message = m.t("Removed %q from %d task(s)", tag, count)
+		default:
+			m.message = m.t("Tagged %d task(s) with %q", count, tag)
+		}
+
+		currentID := ""
+		if m.hasCurrentTask() {
+			currentID = m.getCurrentTask().ID
+		}
+		m.refreshTasks()
+		m.cursor = m.indexOfTask(currentID)
+		m.tagTargets = nil
+		m.viewMode = ModeList
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.searchQuery = ""
+		m.refreshTasks()
+		m.viewMode = ModeList
+		m.message = m.t("Search cleared")
+		m.cursor = 0
+		return m, nil
+
+	case tea.KeyTab:
+		m.searchAll = !m.searchAll
+		m.message = m.searchHint()
+		return m, nil
+
+	case tea.KeyEnter:
+		m.searchQuery = strings.TrimSpace(m.textInput.Value())
+		m.refreshTasks()
+		m.viewMode = ModeList
+		m.cursor = 0
+		if m.searchQuery == "" {
+			m.message = m.t("Search cleared")
+		} else {
+			m.message = m.t("Found %d tasks matching %q", len(m.tasks), m.searchQuery)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// searchHint describes the search mode keys and the field scope
+func (m model) searchHint() string {
+	if m.searchAll {
+		return m.t("Search all fields (Tab to toggle, Enter to apply, ESC to clear)")
+	}
+	return m.t("Search description only (Tab to toggle, Enter to apply, ESC to clear)")
+}
+
+func (m model) updateNotesMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.viewMode = ModeList
+		m.message = m.t("Notes unchanged")
+		m.editingTaskID = ""
+		return m, nil
+
+	case tea.KeyEnter:
+		notes := strings.TrimSpace(m.textInput.Value())
+		if err := m.store.UpdateNotes(m.editingTaskID, notes); err != nil {
+			m.message = m.t("Error updating task: %v", err)
+		} else {
+			m.message = m.t("Notes saved")
+		}
+		m.refreshTasks()
+		m.cursor = m.indexOfTask(m.editingTaskID)
+		m.editingTaskID = ""
+		m.viewMode = ModeList
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateDetailMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "enter", "q":
+		m.viewMode = ModeList
+
+	case "l", "L":
+		if !m.hasCurrentTask() {
+			break
+		}
+		task := m.getCurrentTask()
+		m.linkRemove = msg.String() == "L"
+		if m.linkRemove && len(task.Links) == 0 {
+			m.message = m.t("No links to remove")
+			break
+		}
+		m.editingTaskID = task.ID
+		m.viewMode = ModeLink
+		m.textInput.Reset()
+		m.textInput.Focus()
+		m.message = m.t("URL or file path to add (Enter to save, ESC to cancel)")
+		if m.linkRemove {
+			m.message = m.t("Number or text of the link to remove (Enter to remove, ESC to cancel)")
+		}
+		return m, textinput.Blink
+
+	case "O":
+		if !m.hasCurrentTask() {
+			break
+		}
+		task := m.getCurrentTask()
+		if len(task.Links) == 0 {
+			m.message = m.t("No links to open")
+			break
+		}
+		m.message = m.t("Opening %s", task.Links[0])
+		return m, openLink(task.Links[0])
+	}
+	return m, nil
+}
+
+func (m model) updateLinkMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.viewMode = ModeDetail
+		m.message = m.t("Links unchanged")
+		m.editingTaskID = ""
+		return m, nil
+
+	case tea.KeyEnter:
+		link := strings.TrimSpace(m.textInput.Value())
+		if link == "" {
+			m.message = m.t("Link cannot be empty")
+			return m, nil
+		}
+
+		var err error
+		if m.linkRemove {
+			if task, ok := m.store.Get(m.editingTaskID); ok {
+				// Links can be picked by their number in the list
+				if n, convErr := strconv.Atoi(link); convErr == nil && n >= 1 && n <= len(task.Links) {
+					link = task.Links[n-1]
+				}
+			}
+			err = m.store.RemoveLink(m.editingTaskID, link)
+		} else {
+			err = m.store.AddLink(m.editingTaskID, link)
+		}
+		switch {
+		case err != nil:
+			m.message = m.t("Error updating links: %v", err)
+		case m.linkRemove:
+			m.message = m.t("Link removed")
+		default:
+			m.message = m.t("Link added")
+		}
+		m.refreshTasks()
+		m.cursor = m.indexOfTask(m.editingTaskID)
+		m.editingTaskID = ""
+		m.viewMode = ModeDetail
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateTypeAheadMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyEnter:
+		m.viewMode = ModeList
+		m.message = ""
+		return m, nil
+	case tea.KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+	case tea.KeyDown:
+		if m.cursor < len(m.tasks)-1 {
+			m.cursor++
+		}
+		return m, nil
+	case tea.KeyRunes:
+	default:
+		return m, nil
+	}
+
+	if msg.String() == "'" {
+		m.viewMode = ModeList
+		m.message = ""
+		return m, nil
+	}
+	r := msg.Runes[0]
+	if i := nextByFirstLetter(m.tasks, m.cursor, r); i != -1 {
+		m.cursor = i
+		m.message = m.t("Type a letter to jump to the next task starting with it (ESC to stop)")
+	} else {
+		m.message = m.t("No task starts with %q", string(r))
+	}
+	return m, nil
+}
+
+// pickRandomPending returns the index of a random pending task, or -1 if
+// there are none
+func pickRandomPending(tasks []Task, rng *rand.Rand) int {
+	var pending []int
+	for i, task := range tasks {
+		if task.Status == StatusPending {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return -1
+	}
+	return pending[rng.Intn(len(pending))]
+}
+
+// nextByFirstLetter returns the index of the next task after from whose
+// description starts with r, ignoring case and wrapping around, so
+// repeated presses cycle through the matches. It returns -1 if none does.
+func nextByFirstLetter(tasks []Task, from int, r rune) int {
+	r = unicode.ToLower(r)
+	for step := 1; step <= len(tasks); step++ {
+		i := (from + step) % len(tasks)
+		first, _ := utf8.DecodeRuneInString(strings.TrimSpace(tasks[i].Description))
+		if unicode.ToLower(first) == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m model) updateConfirmMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		confirm := m.confirm
+		m.confirm = nil
+		m.viewMode = ModeList
+		if confirm != nil {
+			confirm.run(&m)
+		}
+
+	case "n", "N", "esc":
+		m.confirm = nil
+		m.viewMode = ModeList
+		m.message = m.t("Cancelled")
+	}
+
+	return m, nil
+}
+
+// switchStore swaps the store between the project and global task lists,
+// saving the current one first
+func (m *model) switchStore() {
+	if m.localDir == "" {
+		m.message = m.t("No project task list here (run patodo init to create one)")
+		return
+	}
+
+	dir, switched := m.localDir, m.t("Switched to the project task list")
+	if m.store.Dir() == m.localDir {
+		global, err := dataDir()
+		if err != nil {
+			m.message = m.t("Error switching task list: %v", err)
+			return
+		}
+		dir, switched = global, m.t("Switched to the global task list")
+	}
+
+	if err := m.store.Flush(); err != nil {
+		m.message = m.t("Error saving tasks: %v", err)
+		return
+	}
+	store, err := newTaskStoreIn(m.config, dir)
+	if err != nil {
+		m.message = m.t("Error switching task list: %v", err)
+		return
+	}
+	// Only set before the program starts, so it's safe to read here
+	if m.store.deferred {
+		store.DeferSaves()
+	}
+
+	m.store = store
+	m.marked = nil
+	m.refreshTasks()
+	m.cursor = 0
+	m.message = switched
+	if warning := m.loadWarning(); warning != "" {
+		m.message = warning
+	}
+}
+
+// loadWarning sums up what the store repaired when it was last loaded, or
+// returns "" if nothing was. The full warnings would print over the TUI.
+func (m model) loadWarning() string {
+	if n := len(m.store.LoadWarnings()); n > 0 {
+		return m.t("Gave new IDs to %d tasks that shared an ID with another task", n)
+	}
+	return ""
+}
+
+// storeLabel names the active task list for the header when there's a
+// project list to switch to; otherwise there's nothing to tell apart
+func (m model) storeLabel() string {
+	if m.localDir == "" {
+		return ""
+	}
+	if m.store.Dir() == m.localDir {
+		return m.t("project")
+	}
+	return m.t("global")
+}
+
+// confirmOrRun runs action right away, or asks for confirmation first when
+// it affects more tasks than the configured threshold
+func (m *model) confirmOrRun(affected int, prompt string, action func(m *model)) tea.Cmd {
+	if affected <= m.config.ConfirmThreshold {
+		action(m)
+		return nil
+	}
+
+	return m.askConfirm(prompt, action)
+}
+
+// askConfirm switches to ModeConfirm to run action once the user answers y.
+// The returned command cancels the confirmation after the configured
+// timeout, if there is one.
+func (m *model) askConfirm(prompt string, action func(m *model)) tea.Cmd {
+	m.confirmSeq++
+	m.confirm = &confirmation{prompt: prompt, run: action, seq: m.confirmSeq}
+	m.viewMode = ModeConfirm
+	m.message = m.t("%s (y/n)", prompt)
+
+	if m.config.ConfirmTimeoutSeconds <= 0 {
+		return nil
+	}
+	seq := m.confirmSeq
+	return tea.Tick(time.Duration(m.config.ConfirmTimeoutSeconds)*time.Second, func(time.Time) tea.Msg {
+		return confirmTimeoutMsg{seq: seq}
+	})
+}
+
+// categoryLabel names a category for messages, including the empty one
+func (m model) categoryLabel(category TaskCategory) string {
+	if category == "" {
+		return m.t("no category")
+	}
+	return string(category)
+}
+
+// targetTaskIDs returns the selected tasks' IDs, or the current task's ID
+// when nothing is selected
+func (m model) targetTaskIDs() []string {
+	if len(m.marked) > 0 {
+		var ids []string
+		for _, task := range m.tasks {
+			if m.marked[task.ID] {
+				ids = append(ids, task.ID)
+			}
+		}
+		return ids
+	}
+	if m.hasCurrentTask() {
+		return []string{m.getCurrentTask().ID}
+	}
+	return nil
+}
+
+func (m model) updateFilterMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.viewMode = ModeList
+		m.message = m.t("Filter cancelled")
+		return m, nil
+
+	case "a":
+		m.pushFilterHistory()
+		m.setFilter(filterState{})
+		m.refreshTasks()
+		m.viewMode = ModeList
+		m.message = m.t("Showing all tasks")
+		m.cursor = 0
+
+	case "p":
+		m.applyStatusFilter(StatusPending, m.t("Showing pending tasks"))
+
+	case "i":
+		m.applyStatusFilter(StatusInProgress, m.t("Showing in-progress tasks"))
+
+	case "d":
+		m.applyStatusFilter(StatusDone, m.t("Showing done tasks"))
+
+	case "t":
+		m.pushFilterHistory()
+		m.filterActionable = true
+		m.refreshTasks()
+		m.viewMode = ModeList
+		m.message = m.t("Showing actionable tasks (not done, not blocked)")
+		m.cursor = 0
+
+	case "s":
+		m.pushFilterHistory()
+		m.filterStalled = true
+		m.refreshTasks()
+		m.viewMode = ModeList
+		m.message = m.t("Showing tasks in progress for over %d days without updates", m.config.StalledDays)
+		m.cursor = 0
+
+	case "u":
+		m.pushFilterHistory()
+		m.filterRecent = true
+		m.refreshTasks()
+		m.viewMode = ModeList
+		m.message = m.t("Showing tasks updated in the last 7 days")
+		m.cursor = 0
+
+	case "c":
+		m.viewMode = ModeFilterCategory
+		m.message = m.t("Select category to filter by")
+
+	case "b":
+		m.builder = m.currentFilter()
+		m.builderField = builderStatus
+		m.viewMode = ModeFilterBuilder
+		m.message = m.t("↑/↓ choose field, ←/→ change value, Enter to apply, ESC to cancel")
+	}
+
+	return m, nil
+}
+
+func (m model) updateFilterBuilderMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.viewMode = ModeList
+		m.message = m.t("Filter cancelled")
+
+	case "enter":
+		m.pushFilterHistory()
+		m.setFilter(m.builder)
+		m.refreshTasks()
+		m.viewMode = ModeList
+		m.message = m.t("Filter: %s", m.filterInfo())
+		m.cursor = 0
+
+	case "up", "k":
+		m.builderField = (m.builderField + builderFieldCount - 1) % builderFieldCount
+
+	case "down", "j", "tab":
+		m.builderField = (m.builderField + 1) % builderFieldCount
+
+	case "right", "l", " ":
+		m.cycleBuilderField(1)
+
+	case "left", "h":
+		m.cycleBuilderField(-1)
+	}
+
+	return m, nil
+}
+
+// cycleBuilderField steps the selected filter builder field to its next
+// (step 1) or previous (step -1) value; every field includes "any"
+func (m *model) cycleBuilderField(step int) {
+	switch m.builderField {
+	case builderStatus:
+		options := []TaskStatus{"", StatusPending, StatusInProgress, StatusDone}
+		current := 0
+		for i, status := range options {
+			if m.builder.status != nil && *m.builder.status == status {
+				current = i
+			}
+		}
+		next := options[(current+step+len(options))%len(options)]
+		m.builder.status = nil
+		if next != "" {
+			m.builder.status = &next
+		}
+
+	case builderCategory:
+		options := append([]string{""}, m.store.GetCategories()...)
+		current := 0
+		for i, category := range options {
+			if m.builder.category != nil && string(*m.builder.category) == category {
+				current = i
+			}
+		}
+		next := TaskCategory(options[(current+step+len(options))%len(options)])
+		m.builder.category = nil
+		if next != "" {
+			m.builder.category = &next
+		}
+
+	case builderActionable:
+		m.builder.actionable = !m.builder.actionable
+
+	case builderDue:
+		count := int(DueThisWeek) + 1
+		m.builder.due = dueWindow((int(m.builder.due) + step + count) % count)
+	}
+}
+
+func (m model) updateFilterCategoryMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.viewMode = ModeList
+		m.message = m.t("Filter cancelled")
+		return m, nil
+
+	case "a":
+		m.pushFilterHistory()
+		m.filterCategory = nil
+		m.refreshTasks()
+		m.viewMode = ModeList
+		m.message = m.t("Showing all categories")
+		m.cursor = 0
+		return m, nil
+	}
+
+	// Check if user pressed a number key for category selection
+	categories := m.store.GetCategories()
+	if len(msg.String()) == 1 && msg.String()[0] >= '1' && msg.String()[0] <= '9' {
+		idx := int(msg.String()[0] - '1')
+		if idx < len(categories) {
+			categoryStr := categories[idx]
+			category := TaskCategory(categoryStr)
+			m.pushFilterHistory()
+			m.filterCategory = &category
+			m.refreshTasks()
+			m.viewMode = ModeList
+			m.message = m.t("Showing tasks in category: %s", categoryStr)
+			m.cursor = 0
+		}
+	}
+
+	return m, nil
+}
+
+func (m *model) refreshTasks() {
+	opts := FilterOptions{
+		Status:     m.filterStatus,
+		Category:   m.filterCategory,
+		Actionable: m.filterActionable,
+		DueBy:      m.filterDue.dueBy(time.Now()),
+
+		Query:          m.searchQuery,
+		QueryAllFields: m.searchAll,
+	}
+	if m.filterStalled {
+		cutoff := m.stalledCutoff()
+		opts.StalledBefore = &cutoff
+	}
+	if m.filterRecent {
+		after := time.Now().Add(-recentWindow)
+		opts.UpdatedAfter = &after
+	}
+	m.futureHidden = 0
+	if m.hideFuture {
+		now := time.Now()
+		opts.StartedBy = &now
+		for _, task := range m.store.GetAll() {
+			if isFutureStart(task, now) {
+				m.futureHidden++
+			}
+		}
+	}
+	m.tasks = m.store.Filter(opts)
+
+	// Order tasks by section so the cursor moves through them as displayed:
+	// pinned tasks first, then status groups when grouping is on
+	sort.SliceStable(m.tasks, func(i, j int) bool {
+		a, b := m.tasks[i], m.tasks[j]
+		if m.pinnedRow(a) != m.pinnedRow(b) {
+			return m.pinnedRow(a)
+		}
+		if m.config.PinNextAction && a.NextAction != b.NextAction {
+			return a.NextAction
+		}
+		if m.groupByStatus && m.groupRank(a.Status) != m.groupRank(b.Status) {
+			return m.groupRank(a.Status) < m.groupRank(b.Status)
+		}
+		if m.sortKey == "due" && (a.DueDate == nil) != (b.DueDate == nil) {
+			return a.DueDate != nil // undated tasks last, even reversed
+		}
+		if m.sortReverse {
+			return m.sortLess(b, a)
+		}
+		return m.sortLess(a, b)
+	})
+
+	m.refreshDueSoon()
+	m.reminders = m.store.DueReminders(time.Now())
+}
+
+// sortLess orders two tasks by the active sort key
+func (m model) sortLess(a, b Task) bool {
+	switch m.sortKey {
+	case "created":
+		return a.CreatedAt.Before(b.CreatedAt)
+	case "updated":
+		return a.UpdatedAt.Before(b.UpdatedAt)
+	case "due":
+		if a.DueDate == nil || b.DueDate == nil {
+			return false
+		}
+		return a.DueDate.Before(*b.DueDate)
+	case "description":
+		return strings.ToLower(a.Description) < strings.ToLower(b.Description)
+	case "status":
+		return m.groupRank(a.Status) < m.groupRank(b.Status)
+	case "category":
+		return a.Category < b.Category
+	}
+	return false
+}
+
+// stalledCutoff returns the time before which an in-progress task's last
+// update makes it stalled
+func (m model) stalledCutoff() time.Time {
+	return time.Now().AddDate(0, 0, -m.config.StalledDays)
+}
+
+// tooSmall reports whether the terminal is known to be smaller than the
+// TUI can draw into
+func (m model) tooSmall() bool {
+	return (m.width > 0 && m.width < minWidth) || (m.height > 0 && m.height < minHeight)
+}
+
+// currentWork returns the only in-progress task in the store. It reports
+// false when there are none or several, since then there's no single
+// current task to show.
+func (m model) currentWork() (Task, bool) {
+	var wip []Task
+	for _, task := range m.store.GetAll() {
+		if task.Status == StatusInProgress {
+			wip = append(wip, task)
+		}
+	}
+	if len(wip) != 1 {
+		return Task{}, false
+	}
+	return wip[0], true
+}
+
+// isStalledInProgress reports whether a task has been in progress without
+// updates for longer than the configured number of days
+func (m model) isStalledInProgress(task Task) bool {
+	return m.config.StalledDays > 0 &&
+		task.Status == StatusInProgress &&
+		task.UpdatedAt.Before(m.stalledCutoff())
+}
+
+// refreshDueSoon recomputes the tasks listed in the due-soon banner
+func (m *model) refreshDueSoon() {
+	m.dueSoon = nil
+	if m.config.DueSoonHours <= 0 {
+		return
+	}
+
+	now := time.Now()
+	window := time.Duration(m.config.DueSoonHours) * time.Hour
+	for _, task := range m.store.GetAll() {
+		if isDueSoon(task, now, window) {
+			m.dueSoon = append(m.dueSoon, task)
+		}
+	}
+	sort.SliceStable(m.dueSoon, func(i, j int) bool {
+		return m.dueSoon[i].DueDate.Before(*m.dueSoon[j].DueDate)
+	})
+}
+
+// showDueSoon reports whether the due-soon banner should be shown
+func (m model) showDueSoon() bool {
+	return !m.dueSoonHidden && len(m.dueSoon) > 0
+}
+
+// showReminders reports whether the reminder banner should be shown
+func (m model) showReminders() bool {
+	return !m.remindersHidden && len(m.reminders) > 0
+}
+
+// jumpToStatus moves the cursor to the next (step 1) or previous (step -1)
+// task with the configured jump status, wrapping around at the ends
+func (m *model) jumpToStatus(step int) {
+	status := m.config.JumpStatus
+	if !isValidStatus(status) {
+		status = StatusInProgress
+	}
+
+	for i := 1; i <= len(m.tasks); i++ {
+		idx := ((m.cursor+i*step)%len(m.tasks) + len(m.tasks)) % len(m.tasks)
+		if m.tasks[idx].Status == status {
+			m.cursor = idx
+			return
+		}
+	}
+	m.message = m.t("No %s tasks in view", m.statusName(status))
+}
+
+// cycleCategory moves the current task to the next (step 1) or previous
+// (step -1) existing category, wrapping around at the ends
+func (m *model) cycleCategory(step int) {
+	if !m.hasCurrentTask() {
+		return
+	}
+
+	categories := m.store.GetCategories()
+	if len(categories) == 0 {
+		m.message = m.t("No categories to cycle through")
+		return
+	}
+
+	task := m.getCurrentTask()
+	next := 0
+	if step < 0 {
+		next = len(categories) - 1
+	}
+	for i, category := range categories {
+		if TaskCategory(category) == task.Category {
+			next = (i + step + len(categories)) % len(categories)
+			break
+		}
+	}
+
+	category := TaskCategory(categories[next])
+	if err := m.store.UpdateCategory(task.ID, category); err != nil {
+		m.message = m.t("Error updating category: %v", err)
+		return
+	}
+	m.message = m.t("Category: %s", category)
+	m.refreshTasks()
+	m.cursor = m.indexOfTask(task.ID)
+}
+
+// saveLastTask remembers the task under the cursor so the next session
+// starts on it. Errors are ignored since the program is exiting.
+func (m *model) saveLastTask() {
+	id := ""
+	if m.hasCurrentTask() {
+		id = m.getCurrentTask().ID
+	}
+	if id == m.config.LastTaskID {
+		return
+	}
+	m.config.LastTaskID = id
+	_ = m.config.Save()
+}
+
+// clearCategory removes the current task's category
+func (m *model) clearCategory() {
+	if !m.hasCurrentTask() {
+		return
+	}
+
+	task := m.getCurrentTask()
+	if task.Category == "" {
+		m.message = m.t("Task has no category")
+		return
+	}
+	if err := m.store.UpdateCategory(task.ID, ""); err != nil {
+		m.message = m.t("Error updating category: %v", err)
+		return
+	}
+	m.message = m.t("Category cleared")
+	m.refreshTasks()
+	m.cursor = m.indexOfTask(task.ID)
+}
+
+// indexOfTask returns the position of a task in the current view, or 0 if
+// it isn't visible
+func (m model) indexOfTask(id string) int {
+	for i, task := range m.tasks {
+		if task.ID == id {
+			return i
+		}
+	}
+	return 0
+}
+
+// nextIncomplete finds the first task after position from in the previous
+// display order that is still listed and not done, wrapping around to the
+// top. It returns the task's index in the refreshed list.
+func (m model) nextIncomplete(order []string, from int) (int, bool) {
+	for step := 1; step < len(order); step++ {
+		id := order[(from+step)%len(order)]
+		for i, task := range m.tasks {
+			if task.ID == id && task.Status != StatusDone {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// hasCurrentTask checks if there's a valid task at the cursor position
+func (m model) hasCurrentTask() bool {
+	return len(m.tasks) > 0 && m.cursor < len(m.tasks)
+}
+
+// getCurrentTask returns the task at the cursor position
+func (m model) getCurrentTask() Task {
+	return m.tasks[m.cursor]
+}
+
+// updateTaskStatus updates the status of the current task and refreshes.
+// It returns a command ringing the bell when a pending task is completed
+// and the completion bell is enabled.
+func (m *model) updateTaskStatus(status TaskStatus) tea.Cmd {
+	if !m.hasCurrentTask() {
+		return nil
+	}
+
+	task := m.getCurrentTask()
+	if err := m.store.UpdateStatus(task.ID, status); err != nil {
+		m.message = m.t("Error updating task: %v", err)
+		m.refreshTasks()
+		return nil
+	}
+	m.refreshTasks()
+
+	if m.config.CompletionBell && ringsBell(task.Status, status) {
+		return m.ringBell()
+	}
+	return nil
+}
+
+// ringsBell reports whether a status change rings the completion bell
+func ringsBell(from, to TaskStatus) bool {
+	return from == StatusPending && to == StatusDone
+}
+
+// ringBell returns a command writing the terminal bell to the program's
+// output. It's a single write, so it can't land inside an escape sequence
+// the renderer is writing.
+func (m model) ringBell() tea.Cmd {
+	if m.output == nil {
+		return nil
+	}
+	out := m.output
+	return func() tea.Msg {
+		_, _ = io.WriteString(out, "\a")
+		return nil
+	}
+}
+
+// applyStatusFilter applies a status filter and returns to list mode
+func (m *model) applyStatusFilter(status TaskStatus, message string) {
+	m.pushFilterHistory()
+	m.filterStatus = &status
+	m.refreshTasks()
+	m.viewMode = ModeList
+	m.message = message
+	m.cursor = 0
+}
+
+// statusFilterCycle is the order the t key steps through; "" clears the
+// status filter
+var statusFilterCycle = []TaskStatus{"", StatusPending, StatusInProgress, StatusDone}
+
+// cycleStatusFilter moves the status filter to the next entry in
+// statusFilterCycle, leaving the other filters alone
+func (m *model) cycleStatusFilter() {
+	current := TaskStatus("")
+	if m.filterStatus != nil {
+		current = *m.filterStatus
+	}
+	next := statusFilterCycle[0]
+	for i, status := range statusFilterCycle {
+		if status == current {
+			next = statusFilterCycle[(i+1)%len(statusFilterCycle)]
+			break
+		}
+	}
+
+	m.pushFilterHistory()
+	if next == "" {
+		m.filterStatus = nil
+	} else {
+		m.filterStatus = &next
 	}
+	m.refreshTasks()
+	m.cursor = 0
+	m.message = m.t("Filter: %s", m.filterInfo())
+}
 
-	return m, nil
+// pushFilterHistory records the current filters before they change
+func (m *model) pushFilterHistory() {
+	m.filterHistory = append(m.filterHistory, m.currentFilter())
+	if len(m.filterHistory) > maxFilterHistory {
+		m.filterHistory = m.filterHistory[len(m.filterHistory)-maxFilterHistory:]
+	}
 }
 
-func (m *model) refreshTasks() {
-	opts := FilterOptions{
-		Status:   m.filterStatus,
-		Category: m.filterCategory,
+// popFilterHistory restores the most recent previous filters.
+// It returns false if there is nothing to restore.
+func (m *model) popFilterHistory() bool {
+	if len(m.filterHistory) == 0 {
+		return false
 	}
-	m.tasks = m.store.Filter(opts)
+
+	prev := m.filterHistory[len(m.filterHistory)-1]
+	m.filterHistory = m.filterHistory[:len(m.filterHistory)-1]
+	m.setFilter(prev)
+	m.refreshTasks()
+	m.cursor = 0
+	return true
 }
 
-// hasCurrentTask checks if there's a valid task at the cursor position
-func (m model) hasCurrentTask() bool {
-	return len(m.tasks) > 0 && m.cursor < len(m.tasks)
+// currentFilter returns the active filters
+func (m model) currentFilter() filterState {
+	return filterState{
+		status:     m.filterStatus,
+		category:   m.filterCategory,
+		actionable: m.filterActionable,
+		due:        m.filterDue,
+		stalled:    m.filterStalled,
+		recent:     m.filterRecent,
+	}
 }
 
-// getCurrentTask returns the task at the cursor position
-func (m model) getCurrentTask() Task {
-	return m.tasks[m.cursor]
+// setFilter replaces the active filters; the caller refreshes the tasks
+func (m *model) setFilter(f filterState) {
+	m.filterStatus = f.status
+	m.filterCategory = f.category
+	m.filterActionable = f.actionable
+	m.filterDue = f.due
+	m.filterStalled = f.stalled
+	m.filterRecent = f.recent
 }
 
-// updateTaskStatus updates the status of the current task and refreshes
-func (m *model) updateTaskStatus(status TaskStatus) {
-	if m.hasCurrentTask() {
-		task := m.getCurrentTask()
-		if err := m.store.UpdateStatus(task.ID, status); err != nil {
-			m.message = fmt.Sprintf("Error updating task: %v", err)
-		}
-		m.refreshTasks()
+// showTaskCountWarning reports whether the store has grown past the
+// configured warning threshold and the warning hasn't been dismissed
+func (m model) showTaskCountWarning() bool {
+	if m.warningHidden || m.config.TaskWarningThreshold <= 0 {
+		return false
 	}
+	return len(m.store.GetAll()) > m.config.TaskWarningThreshold
 }
 
-// applyStatusFilter applies a status filter and returns to list mode
-func (m *model) applyStatusFilter(status TaskStatus, message string) {
-	m.filterStatus = &status
-	m.refreshTasks()
-	m.viewMode = ModeList
-	m.message = message
-	m.cursor = 0
+// filterInfo describes the active filters for display
+func (m model) filterInfo() string {
+	var parts []string
+	if m.filterStatus != nil {
+		parts = append(parts, m.statusName(*m.filterStatus))
+	}
+	if m.filterCategory != nil {
+		parts = append(parts, string(*m.filterCategory))
+	}
+	if m.filterActionable {
+		parts = append(parts, m.t("actionable"))
+	}
+	if m.filterDue != DueAny {
+		parts = append(parts, m.t(m.filterDue.String()))
+	}
+	if m.filterStalled {
+		parts = append(parts, m.t("stalled"))
+	}
+	if m.filterRecent {
+		parts = append(parts, m.t("updated this week"))
+	}
+	if m.searchQuery != "" {
+		parts = append(parts, fmt.Sprintf("%q", m.searchQuery))
+	}
+	if m.futureHidden > 0 {
+		parts = append(parts, m.t("%d not started", m.futureHidden))
+	}
+	if len(parts) == 0 {
+		return m.t("all")
+	}
+	return strings.Join(parts, " + ")
 }
 
 func (m model) View() string {
 	if m.quitting {
-		return "Goodbye!\n"
+		if m.discarded {
+			return m.t("Goodbye! (unsaved changes discarded)") + "\n"
+		}
+		return m.t("Goodbye!") + "\n"
+	}
+	if m.tooSmall() {
+		return m.t("Terminal too small — resize to at least %dx%d", minWidth, minHeight) + "\n"
 	}
 
 	var s strings.Builder
@@ -419,11 +2515,66 @@ func (m model) View() string {
 		Bold(true).
 		Foreground(lipgloss.Color(colorTitle)).
 		MarginBottom(1)
-	s.WriteString(titleStyle.Render("📝 patodo"))
+	title := cmp.Or(m.config.Title, m.t(defaultTitle))
+	if wip, ok := m.currentWork(); ok {
+		titleStyle = titleStyle.Foreground(lipgloss.Color(colorInProgress))
+		title = m.t("▶ Working on: %s", wip.Description)
+	}
+	if info := m.filterInfo(); info != m.t("all") {
+		total := len(m.store.GetAll())
+		title += " · " + info + " · " + m.t("showing %d of %d (%d filtered)", len(m.tasks), total, total-len(m.tasks))
+	}
+	if label := m.storeLabel(); label != "" {
+		title += " · " + label
+	}
+	if m.saveStatus != "" {
+		title += " · " + m.saveStatus
+	}
+	s.WriteString(titleStyle.Render(title))
 	s.WriteString("\n\n")
 
-	// Message bar (above content)
-	if m.message != "" {
+	if m.showTaskCountWarning() {
+		warningStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(colorWarning)).
+			Bold(true)
+		warning := m.t("⚠ %d tasks stored - consider archiving old tasks to keep patodo fast ([w] dismiss)", len(m.store.GetAll()))
+		s.WriteString(warningStyle.Render(warning))
+		s.WriteString("\n\n")
+	}
+
+	if m.showReminders() {
+		bannerStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(colorWarning)).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color(colorWarning)).
+			Padding(0, 1)
+		var banner strings.Builder
+		banner.WriteString(m.t("🔔 Reminders ([w] dismiss):"))
+		for _, task := range m.reminders {
+			banner.WriteString(fmt.Sprintf("\n  • %s (%s)", task.Description, task.RemindAt.Format("Mon 15:04")))
+		}
+		s.WriteString(bannerStyle.Render(banner.String()))
+		s.WriteString("\n\n")
+	}
+
+	if m.showDueSoon() {
+		bannerStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(colorWarning)).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color(colorWarning)).
+			Padding(0, 1)
+		var banner strings.Builder
+		banner.WriteString(m.t("⏰ Due in the next %dh ([w] dismiss):", m.config.DueSoonHours))
+		for _, task := range m.dueSoon {
+			banner.WriteString(fmt.Sprintf("\n  • %s (%s)", task.Description, task.DueDate.Format("Mon 15:04")))
+		}
+		s.WriteString(bannerStyle.Render(banner.String()))
+		s.WriteString("\n\n")
+	}
+
+	// Message bar (above content). Quiet mode hides it in list mode only,
+	// since other modes use it for their prompts.
+	if m.message != "" && (m.config.ShowMessages || m.viewMode != ModeList) {
 		messageStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color(colorMessage)).
 			Background(lipgloss.Color("236")).
@@ -435,32 +2586,100 @@ func (m model) View() string {
 		s.WriteString("\n\n")
 	}
 
+	if m.showWelcome {
+		welcomeStyle := lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(colorTitle)).
+			Padding(0, 1)
+		welcome := m.t("Welcome to patodo! 👋\n\n" +
+			"[n] create a task    [e] edit it\n" +
+			"[d] mark done        [i] in-progress\n" +
+			"[f] filter tasks     [x] delete\n" +
+			"[j/k] move around    [q] quit\n\n" +
+			"Press any key to get started.")
+		s.WriteString(welcomeStyle.Render(welcome))
+		s.WriteString("\n\n")
+	}
+
 	switch m.viewMode {
-	case ModeCreate:
-		s.WriteString("Description:\n")
+	case ModeCreate, ModeEdit:
+		s.WriteString(m.t("Description:") + "\n")
 		s.WriteString(m.textInput.View())
+		s.WriteString(m.overflowIndicator(m.textInput))
 		s.WriteString("\n\n")
-		s.WriteString("Category:\n")
+		s.WriteString(m.t("Category:") + "\n")
 		s.WriteString(m.categoryInput.View())
+		s.WriteString(m.overflowIndicator(m.categoryInput))
 		s.WriteString("\n\n")
-	case ModeEdit:
-		s.WriteString("Description:\n")
+		if preview, ok := m.editPreview(); ok {
+			s.WriteString(m.t("Preview:") + "\n")
+			s.WriteString(preview)
+			s.WriteString("\n\n")
+		}
+	case ModeCategoryPicker:
+		s.WriteString(m.categoryPicker.View())
+		s.WriteString("\n")
+	case ModeRename:
+		s.WriteString(m.t("Description:") + "\n")
 		s.WriteString(m.textInput.View())
 		s.WriteString("\n\n")
-		s.WriteString("Category:\n")
-		s.WriteString(m.categoryInput.View())
+	case ModeBlockReason:
+		s.WriteString(m.t("Blocked by:") + "\n")
+		s.WriteString(m.textInput.View())
 		s.WriteString("\n\n")
 	case ModeFilterCategory:
 		// Show available categories
 		categories := m.store.GetCategories()
 		if len(categories) > 0 {
-			s.WriteString("Select category:\n")
+			s.WriteString(m.t("Select category:") + "\n")
 			for i, cat := range categories {
 				s.WriteString(fmt.Sprintf("  [%d] %s\n", i+1, cat))
 			}
-			s.WriteString("  [a] All categories\n")
+			s.WriteString("  [a] " + m.t("All categories") + "\n")
 		} else {
-			s.WriteString("No categories yet.\n")
+			s.WriteString(m.t("No categories yet.") + "\n")
+		}
+		s.WriteString("\n")
+	case ModeFilterBuilder:
+		s.WriteString(m.renderFilterBuilder())
+		s.WriteString("\n")
+	case ModeSearch:
+		s.WriteString(m.t("Search:") + "\n")
+		s.WriteString(m.textInput.View())
+		s.WriteString("\n\n")
+	case ModeCommand:
+		s.WriteString(m.t("Command:") + "\n")
+		s.WriteString(m.textInput.View())
+		s.WriteString("\n\n")
+	case ModeStartDate:
+		s.WriteString(m.t("Start date:") + "\n")
+		s.WriteString(m.textInput.View())
+		s.WriteString("\n\n")
+	case ModeReminder:
+		s.WriteString(m.t("Remind at:") + "\n")
+		s.WriteString(m.textInput.View())
+		s.WriteString("\n\n")
+	case ModeTag:
+		s.WriteString(m.t("Tag:") + "\n")
+		s.WriteString(m.textInput.View())
+		s.WriteString("\n\n")
+	case ModeLink:
+		if task, ok := m.store.Get(m.editingTaskID); ok && m.linkRemove {
+			for i, link := range task.Links {
+				s.WriteString(fmt.Sprintf("  %d. %s\n", i+1, link))
+			}
+			s.WriteString("\n")
+		}
+		s.WriteString(m.t("Link:") + "\n")
+		s.WriteString(m.textInput.View())
+		s.WriteString("\n\n")
+	case ModeNotes:
+		s.WriteString(m.t("Notes:") + "\n")
+		s.WriteString(m.textInput.View())
+		s.WriteString("\n\n")
+	case ModeDetail:
+		if m.hasCurrentTask() {
+			s.WriteString(m.renderDetail(m.getCurrentTask()))
 		}
 		s.WriteString("\n")
 	case ModeFilter:
@@ -471,7 +2690,12 @@ func (m model) View() string {
 			emptyStyle := lipgloss.NewStyle().
 				Foreground(lipgloss.Color(colorEmpty)).
 				Italic(true)
-			s.WriteString(emptyStyle.Render("No tasks yet. Press 'n' to create one!"))
+			emptyText := cmp.Or(m.config.EmptyMessage, m.t(defaultEmptyMessage))
+			if len(m.store.GetAll()) > len(m.tasks) {
+				// Tasks exist but the active filter hides all of them
+				emptyText = m.t("No tasks match the current filter (press f→a to clear)")
+			}
+			s.WriteString(emptyStyle.Render(emptyText))
 			s.WriteString("\n\n")
 		} else {
 			if m.viewAsTable {
@@ -483,88 +2707,34 @@ func (m model) View() string {
 					BorderBottom(true).
 					BorderForeground(lipgloss.Color(colorHelp))
 
-				s.WriteString(headerStyle.Render(fmt.Sprintf("%-3s %-50s %-20s", "Status", "Description", "Category")))
+				header := fmt.Sprintf(" %-3s %-*s", m.t("Status"), m.tableDescriptionWidth(), m.t("Description"))
+				if m.config.ShowCategories {
+					header += fmt.Sprintf(" %-20s", m.t("Category"))
+				}
+				header += fmt.Sprintf(" %-6s", m.t("ID"))
+				if m.showCreatedColumn() {
+					header += fmt.Sprintf(" %-12s", m.t("Created"))
+				}
+				s.WriteString(headerStyle.Render(header))
 				s.WriteString("\n")
+			}
 
-				// Render tasks as table rows
-				for i, task := range m.tasks {
-					cursor := " "
-					if i == m.cursor {
-						cursor = ">"
-					}
-
-					statusIcon := m.getStatusIcon(task.Status)
-					statusColor := m.getStatusColor(task.Status)
-
-					// Truncate description if too long
-					description := task.Description
-					if len(description) > 48 {
-						description = description[:45] + "..."
-					}
-
-					// Format category
-					category := string(task.Category)
-					if len(category) > 18 {
-						category = category[:15] + "..."
-					}
-
-					categoryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorCategory)).Italic(true)
-					categoryText := ""
-					if category != "" {
-						categoryText = categoryStyle.Render(category)
-					}
+			groupStyle := lipgloss.NewStyle().
+				Bold(true).
+				Underline(true).
+				Foreground(lipgloss.Color(colorTitle))
 
-					// Build row
-					row := fmt.Sprintf("%-3s ", cursor)
-					statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor))
-					row += statusStyle.Render(fmt.Sprintf("%-3s", statusIcon))
-					row += " "
-
-					if i == m.cursor {
-						descStyle := lipgloss.NewStyle().
-							Bold(true).
-							Foreground(lipgloss.Color(colorTitle))
-						row += descStyle.Render(fmt.Sprintf("%-50s", description))
-					} else {
-						taskStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor))
-						row += taskStyle.Render(fmt.Sprintf("%-50s", description))
+			for i, task := range m.tasks {
+				if header := m.sectionHeader(i); header != "" {
+					if i > 0 {
+						s.WriteString("\n")
 					}
-
-					row += " " + fmt.Sprintf("%-20s", categoryText)
-
-					s.WriteString(row)
+					s.WriteString(groupStyle.Render(header))
 					s.WriteString("\n")
 				}
-			} else {
-				// List view
-				for i, task := range m.tasks {
-					cursor := " "
-					if i == m.cursor {
-						cursor = ">"
-					}
-
-					statusIcon := m.getStatusIcon(task.Status)
-					statusColor := m.getStatusColor(task.Status)
-
-					taskStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor))
-
-					line := fmt.Sprintf("%s %s %s", cursor, statusIcon, task.Description)
-					if task.Category != "" {
-						categoryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorCategory)).Italic(true)
-						line += " " + categoryStyle.Render(fmt.Sprintf("[%s]", string(task.Category)))
-					}
 
-					if i == m.cursor {
-						line = lipgloss.NewStyle().
-							Bold(true).
-							Foreground(lipgloss.Color(colorTitle)).
-							Render(line)
-					} else {
-						line = taskStyle.Render(line)
-					}
-					s.WriteString(line)
-					s.WriteString("\n")
-				}
+				s.WriteString(m.renderTaskRow(task, i == m.cursor, rowOpts{table: m.viewAsTable}))
+				s.WriteString("\n")
 			}
 			s.WriteString("\n")
 		}
@@ -575,38 +2745,476 @@ func (m model) View() string {
 		Foreground(lipgloss.Color(colorHelp)).
 		Faint(true)
 
-	if m.viewMode == ModeList {
-		filterInfo := "all"
-		if m.filterStatus != nil && m.filterCategory != nil {
-			filterInfo = fmt.Sprintf("%s + %s", string(*m.filterStatus), string(*m.filterCategory))
-		} else if m.filterStatus != nil {
-			filterInfo = string(*m.filterStatus)
-		} else if m.filterCategory != nil {
-			filterInfo = string(*m.filterCategory)
-		}
-		viewStyle := "table"
+	if m.viewMode == ModeList && !m.config.ShowHelp {
+		s.WriteString(helpStyle.Render(m.t("? for help")))
+	} else if m.viewMode == ModeList {
+		viewStyle := m.t("table")
 		if !m.viewAsTable {
-			viewStyle = "list"
+			viewStyle = m.t("list")
 		}
-		help := fmt.Sprintf("[n] new task\n[e] edit task\n[v] toggle view (%s)\n[d] done/undone\n[i] in-progress\n[p] pending\n[x] delete\n[f] filter (%s)\n[q] quit", viewStyle, filterInfo)
+		help := m.t("[n] new task\n[e] edit task\n[r] rename task\n[v] toggle view (%s)\n[g] group by status\n[d] done/undone\n[F] finish and go to next\n[i] in-progress\n[p] pending\n[[/]] cycle category\n[-] clear category\n[c] show/hide categories\n[z] wrap/truncate rows\n[{/}] previous/next %s\n[0-3] priority\n[+/_] raise/lower priority\n[>/W] due a day/week later\n[*] pin/unpin\n[N] next action\n[B] bury\n[M] set reminder\n[S] start date\n[H] show/hide not started\n[b] block/unblock\n[space] select\n[a/A] add/remove tag\n[x] delete\n[C] complete category\n[o] notes\n[enter] details\n[/] search\n[R] pick a random pending task\n['] jump by first letter\n[:] command line\n[ctrl+r] reload\n[D] open data folder\n[P] project/global tasks\n[I] show IDs\n[t] cycle status filter\n[f] filter (%s)\n[backspace] previous filter\n[?] hide help\n[q] quit\n[ctrl+x] quit without saving", viewStyle, m.statusName(m.config.JumpStatus), m.filterInfo())
 		s.WriteString(helpStyle.Render(help))
 	}
 
 	return s.String()
 }
 
-func (m model) getStatusIcon(status TaskStatus) string {
+// editPreview renders the task being edited as it would look in the list
+// with the current input values, using the active view's row renderer
+func (m model) editPreview() (string, bool) {
+	if m.viewMode != ModeEdit {
+		return "", false
+	}
+	task, ok := m.store.Get(m.editingTaskID)
+	if !ok {
+		return "", false
+	}
+	task.Description = strings.TrimSpace(m.textInput.Value())
+	task.Category = TaskCategory(strings.TrimSpace(m.categoryInput.Value()))
+	return m.renderTaskRow(task, false, rowOpts{table: m.viewAsTable}), true
+}
+
+// overflowIndicator returns a marker for an input whose text is longer
+// than its visible width, so scrolled-out content isn't missed
+func (m model) overflowIndicator(input textinput.Model) string {
+	length := len([]rune(input.Value()))
+	if input.Width <= 0 || length <= input.Width {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(colorHelp)).
+		Render(m.t(" … (%d chars)", length))
+}
+
+// renderFilterBuilder renders the draft filter, one field per line
+func (m model) renderFilterBuilder() string {
+	status, category, actionable := m.t("any"), m.t("any"), m.t("no")
+	if m.builder.status != nil {
+		status = m.statusName(*m.builder.status)
+	}
+	if m.builder.category != nil {
+		category = string(*m.builder.category)
+	}
+	if m.builder.actionable {
+		actionable = m.t("yes")
+	}
+
+	fields := []struct{ label, value string }{
+		{m.t("Status"), status},
+		{m.t("Category"), category},
+		{m.t("Actionable"), actionable},
+		{m.t("Due"), m.t(m.builder.due.String())},
+	}
+
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorTitle))
+	var s strings.Builder
+	for i, field := range fields {
+		line := fmt.Sprintf("  %-12s %s", field.label+":", field.value)
+		if i == m.builderField {
+			line = selectedStyle.Render(fmt.Sprintf("> %-12s ‹ %s ›", field.label+":", field.value))
+		}
+		s.WriteString(line)
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// rowOpts controls how renderTaskRow lays out a task
+type rowOpts struct {
+	table bool // a fixed-width table row rather than a wrapped list line
+}
+
+// renderTaskRow renders a task as a row of the task list, in the layout
+// picked by opts. Every view that shows tasks as rows goes through it.
+func (m model) renderTaskRow(task Task, selected bool, opts rowOpts) string {
+	if opts.table {
+		return m.renderTableRow(task, selected)
+	}
+	return m.renderListRow(task, selected)
+}
+
+// rowDescription returns the description with the pinned, stalled and
+// blocked markers in front
+func (m model) rowDescription(task Task) string {
+	description := task.Description
+	if task.Blocked {
+		description = "⏸ " + description
+	}
+	if m.isStalledInProgress(task) {
+		description = "⌛ " + description
+	}
+	if task.Pinned {
+		description = "📌 " + description
+	}
+	if task.NextAction {
+		description = "🎯 " + description
+	}
+	if isFutureStart(task, time.Now()) {
+		description = "🗓 " + description
+	}
+	return description
+}
+
+// rowStyle returns the style for a row's text: highlighted when selected,
+// otherwise in the status color and faint while blocked
+func (m model) rowStyle(task Task, selected bool) lipgloss.Style {
+	if selected {
+		return lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(colorTitle))
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.getStatusColor(task.Status))).
+		Faint(task.Blocked)
+}
+
+// categoryStyle returns the style for a category name
+func (m model) categoryStyle(cat TaskCategory) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(m.categoryColor(cat))).Italic(true)
+}
+
+// renderTableRow renders a task as a row of the table view
+func (m model) renderTableRow(task Task, selected bool) string {
+	cursor := " "
+	if selected {
+		cursor = ">"
+	}
+	if m.marked[task.ID] {
+		cursor += "•"
+	}
+
+	statusIcon := m.getStatusIcon(task.Status)
+	statusColor := m.getStatusColor(task.Status)
+
+	// Truncate description if too long, by display width so the emoji
+	// markers aren't cut mid-rune
+	width := m.tableDescriptionWidth()
+	description := ansi.Truncate(m.rowDescription(task), width, "…")
+	description += strings.Repeat(" ", max(width-lipgloss.Width(description), 0))
+
+	// Format category
+	category := string(task.Category)
+	if len(category) > 18 {
+		category = category[:15] + "..."
+	}
+
+	categoryText := ""
+	if category != "" {
+		categoryText = m.highlightQuery(category, m.categoryStyle(task.Category))
+	}
+
+	// Build row
+	row := priorityBorder(task.Priority)
+	row += fmt.Sprintf("%-3s ", cursor)
+	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor))
+	row += statusStyle.Render(fmt.Sprintf("%-3s", statusIcon))
+	row += " "
+	row += m.highlightQuery(description, m.rowStyle(task, selected))
+
+	if m.config.ShowCategories {
+		row += " " + fmt.Sprintf("%-20s", categoryText)
+	}
+	row += " " + lipgloss.NewStyle().Foreground(lipgloss.Color(colorHelp)).Render(fmt.Sprintf("%-6s", fmt.Sprintf("#%d", task.Seq)))
+
+	if m.showCreatedColumn() {
+		createdStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorMessage))
+		row += " " + createdStyle.Render(fmt.Sprintf("%-12s", humanizeTime(task.CreatedAt, time.Now())))
+	}
+	return row + m.rowID(task) + m.notesMatch(task)
+}
+
+// tableDescriptionWidth is the width of the table's Description column,
+// which takes over the Category column's space when categories are hidden
+func (m model) tableDescriptionWidth() int {
+	if m.config.ShowCategories {
+		return 50
+	}
+	return 71
+}
+
+// showCreatedColumn reports whether the table should include the Created
+// column; it's the first one dropped on narrow terminals
+func (m model) showCreatedColumn() bool {
+	return m.config.ShowCreatedColumn && (m.width == 0 || m.width >= tableCreatedMinWidth)
+}
+
+// renderListRow renders a task as a line of the list view
+func (m model) renderListRow(task Task, selected bool) string {
+	cursor := " "
+	if selected {
+		cursor = ">"
+	}
+	mark := " "
+	if m.marked[task.ID] {
+		mark = "•"
+	}
+
+	taskStyle := m.rowStyle(task, selected)
+	prefix := taskStyle.Render(fmt.Sprintf("%s%s %s ", cursor, mark, m.getStatusIcon(task.Status)))
+	line := m.highlightQuery(m.rowDescription(task), taskStyle)
+	if task.Blocked && task.BlockedReason != "" {
+		line += taskStyle.Render(m.t(" (waiting on %s)", task.BlockedReason))
+	}
+	if task.Category != "" && m.config.ShowCategories {
+		categoryStyle := m.categoryStyle(task.Category)
+		line += " " + categoryStyle.Render("[") + m.highlightQuery(string(task.Category), categoryStyle) + categoryStyle.Render("]")
+	}
+	line += m.rowID(task) + m.notesMatch(task)
+	if !m.config.WrapRows {
+		return prefix + m.truncateRow(line, lipgloss.Width(prefix))
+	}
+	return prefix + m.wrapHanging(line, lipgloss.Width(prefix))
+}
+
+// listWrapMinWidth is the narrowest text column worth wrapping into
+const listWrapMinWidth = 10
+
+// wrapHanging wraps text to the terminal width minus indent, indenting the
+// continuation lines so they line up under the first one. Text is left as
+// is while the width is unknown.
+func (m model) wrapHanging(text string, indent int) string {
+	available := m.width - indent
+	if m.width <= 0 || available < listWrapMinWidth {
+		return text
+	}
+	wrapped := ansi.Wrap(text, available, "")
+	return strings.ReplaceAll(wrapped, "\n", "\n"+strings.Repeat(" ", indent))
+}
+
+// truncateRow cuts text to the terminal width minus indent, ending it with
+// an ellipsis. Like wrapHanging, it leaves text as is while the width is
+// unknown.
+func (m model) truncateRow(text string, indent int) string {
+	available := m.width - indent
+	if m.width <= 0 || available < listWrapMinWidth {
+		return text
+	}
+	return ansi.Truncate(text, available, "…")
+}
+
+// highlightQuery renders text in the base style with each occurrence of
+// the active search query shown reversed
+func (m model) highlightQuery(text string, base lipgloss.Style) string {
+	query := strings.ToLower(strings.TrimSpace(m.searchQuery))
+	lower := strings.ToLower(text)
+	if query == "" || len(lower) != len(text) {
+		return base.Render(text)
+	}
+
+	match := base.Reverse(true)
+	var s strings.Builder
+	for {
+		i := strings.Index(lower, query)
+		if i == -1 {
+			break
+		}
+		s.WriteString(base.Render(text[:i]))
+		s.WriteString(match.Render(text[i : i+len(query)]))
+		text, lower = text[i+len(query):], lower[i+len(query):]
+	}
+	s.WriteString(base.Render(text))
+	return s.String()
+}
+
+// rowID shows the task's full ID at the end of its row while IDs are
+// toggled on (I), for use with the CLI commands
+func (m model) rowID(task Task) string {
+	if !m.showIDs {
+		return ""
+	}
+	return " " + lipgloss.NewStyle().Foreground(lipgloss.Color(colorHelp)).Render(task.ID)
+}
+
+// notesMatch shows the notes of a task that matched the search only in
+// its notes, so the match is visible from the list
+func (m model) notesMatch(task Task) string {
+	if field, _ := matchQuery(task, m.searchQuery, m.searchAll); field != fieldNotes {
+		return ""
+	}
+
+	notes := ansi.Truncate(task.Notes, 40, "...")
+	notesStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorMessage))
+	return notesStyle.Render(m.t(" — notes: ")) + m.highlightQuery(notes, notesStyle)
+}
+
+// renderDetail renders every field of a task, one per line
+func (m model) renderDetail(task Task) string {
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorTitle))
+	plain := lipgloss.NewStyle()
+
+	var s strings.Builder
+	field := func(label, value string) {
+		s.WriteString(labelStyle.Render(fmt.Sprintf("%-12s", label+":")))
+		s.WriteString(" " + value + "\n")
+	}
+	// fieldLines writes a field with one value per line, aligned under
+	// the first
+	fieldLines := func(label string, values []string) {
+		for i, value := range values {
+			if i == 0 {
+				field(label, value)
+			} else {
+				s.WriteString(fmt.Sprintf("%-13s%s\n", "", value))
+			}
+		}
+	}
+
+	field(m.t("ID"), fmt.Sprintf("#%d (%s)", task.Seq, shortID(task.ID)))
+	field(m.t("Description"), m.highlightQuery(task.Description, plain))
+	field(m.t("Status"), m.statusName(task.Status))
+	if task.Priority != PriorityNone {
+		field(m.t("Priority"), lipgloss.NewStyle().Foreground(lipgloss.Color(priorityColor(task.Priority))).Render(m.t(task.Priority.String())))
+	}
+	if task.Category != "" {
+		field(m.t("Category"), m.highlightQuery(string(task.Category), plain))
+	}
+	if task.DueDate != nil {
+		field(m.t("Due"), task.DueDate.Format(time.DateOnly))
+	}
+	if task.StartDate != nil {
+		field(m.t("Starts"), task.StartDate.Format(time.DateOnly))
+	}
+	if task.RemindAt != nil {
+		field(m.t("Reminder"), task.RemindAt.Format("2006-01-02 15:04"))
+	}
+	if task.Blocked {
+		field(m.t("Blocked"), task.BlockedReason)
+	}
+	if len(task.Tags) > 0 {
+		field(m.t("Tags"), strings.Join(task.Tags, ", "))
+	}
+	if task.Notes != "" {
+		field(m.t("Notes"), m.highlightQuery(task.Notes, plain))
+	}
+	links := make([]string, len(task.Links))
+	for i, link := range task.Links {
+		links[i] = fmt.Sprintf("%d. %s", i+1, link)
+	}
+	fieldLines(m.t("Links"), links)
+	now := time.Now()
+	field(m.t("Created"), fmt.Sprintf("%s (%s)", task.CreatedAt.Format("2006-01-02 15:04"), humanizeTime(task.CreatedAt, now)))
+	field(m.t("Updated"), fmt.Sprintf("%s (%s)", task.UpdatedAt.Format("2006-01-02 15:04"), humanizeTime(task.UpdatedAt, now)))
+	history := make([]string, len(task.History))
+	for i, change := range task.History {
+		history[i] = fmt.Sprintf("%s  %s → %s", change.At.Format("2006-01-02 15:04"), m.statusName(change.From), m.statusName(change.To))
+	}
+	fieldLines(m.t("History"), history)
+	if matched, _ := matchQuery(task, m.searchQuery, m.searchAll); matched != "" {
+		field(m.t("Matched in"), m.t(matched))
+	}
+
+	s.WriteString("\n" + m.t("[esc/enter] back  [l/L] add/remove link  [O] open first link"))
+	return s.String()
+}
+
+// pinnedRow reports whether a task belongs in the pinned section: pinned
+// tasks, and the next action when pin_next_action is set
+func (m model) pinnedRow(task Task) bool {
+	return task.Pinned || (m.config.PinNextAction && task.NextAction)
+}
+
+// sectionHeader returns the header to render above the task at index i,
+// or "" if it continues the previous task's section. Tasks are already
+// sorted by section in refreshTasks.
+func (m model) sectionHeader(i int) string {
+	task := m.tasks[i]
+	if m.pinnedRow(task) {
+		if i == 0 {
+			return m.t("📌 Pinned")
+		}
+		return ""
+	}
+
+	startsSection := i == 0 || m.pinnedRow(m.tasks[i-1])
+	if m.groupByStatus {
+		if startsSection || m.tasks[i-1].Status != task.Status {
+			return m.t(statusGroupTitle(task.Status))
+		}
+		return ""
+	}
+	if i > 0 && startsSection {
+		return m.t("Tasks")
+	}
+	return ""
+}
+
+// statusGroupTitle returns the header shown above a status group
+func statusGroupTitle(status TaskStatus) string {
 	switch status {
-	case StatusDone:
-		return "✓"
 	case StatusInProgress:
-		return "⟳"
+		return "In Progress"
+	case StatusDone:
+		return "Done"
+	default:
+		return "Pending"
+	}
+}
+
+// groupRank returns the position of a status in the group order
+func (m model) groupRank(status TaskStatus) int {
+	for i, s := range m.groupOrder {
+		if s == status {
+			return i
+		}
+	}
+	return len(m.groupOrder)
+}
+
+// categoryColor returns the configured color for a category, falling back
+// to the default category color
+func (m model) categoryColor(cat TaskCategory) string {
+	color, ok := m.config.CategoryColors[string(cat)]
+	if !ok || color == "" {
+		return colorCategory
+	}
+	if code, ok := namedColors[strings.ToLower(color)]; ok {
+		return code
+	}
+	return color
+}
+
+func (m model) getStatusIcon(status TaskStatus) string {
+	if icon, ok := m.config.StatusIcons[status]; ok && !m.badStatusIcons {
+		return icon
+	}
+	if icon, ok := defaultStatusIcons[status]; ok {
+		return icon
+	}
+	return defaultStatusIcons[StatusPending]
+}
+
+// priorityColor returns the color that marks a priority in the table
+// border and the detail view, or "" for PriorityNone
+func priorityColor(p Priority) string {
+	switch p {
+	case PriorityLow:
+		return colorPriorityLow
+	case PriorityMedium:
+		return colorPriorityMedium
+	case PriorityHigh:
+		return colorPriorityHigh
 	default:
-		return "○"
+		return ""
+	}
+}
+
+// priorityBorder renders the one-column left border of a table row,
+// colored by priority and blank when none is set
+func priorityBorder(p Priority) string {
+	color := priorityColor(p)
+	if color == "" {
+		return " "
 	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("▎")
 }
 
 func (m model) getStatusColor(status TaskStatus) string {
+	return statusColor(status)
+}
+
+// statusColor returns the color a status is shown in, in the TUI and in
+// CLI output to a terminal
+func statusColor(status TaskStatus) string {
 	switch status {
 	case StatusDone:
 		return colorDone
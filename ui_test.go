@@ -1,12 +1,22 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 func TestViewMode(t *testing.T) {
@@ -32,7 +42,7 @@ func createTestModel(t *testing.T) (model, string) {
 		tasks:    []Task{},
 	}
 
-	m := initialModel(store)
+	m := initialModel(store, DefaultConfig())
 	return m, tmpDir
 }
 
@@ -503,6 +513,75 @@ func TestModel_UpdateFilterMode_ShowAll(t *testing.T) {
 	}
 }
 
+func TestModel_FilterHistory_StepBack(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Task 1", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	if err := m.store.Add("Task 2", "personal"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	// Apply a status filter, then a category filter on top
+	m.viewMode = ModeFilter
+	updatedModel, _ := m.updateFilterMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = updatedModel.(model)
+	m.viewMode = ModeFilterCategory
+	updatedModel, _ = m.updateFilterCategoryMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	m = updatedModel.(model)
+
+	if m.filterStatus == nil || m.filterCategory == nil {
+		t.Fatal("Expected both status and category filters to be set")
+	}
+
+	// First step back restores the status-only filter
+	backspace := tea.KeyMsg{Type: tea.KeyBackspace}
+	updatedModel, _ = m.updateListMode(backspace)
+	m = updatedModel.(model)
+
+	if m.filterStatus == nil || *m.filterStatus != StatusPending {
+		t.Error("Expected pending status filter to be restored")
+	}
+	if m.filterCategory != nil {
+		t.Error("Expected category filter to be removed")
+	}
+
+	// Second step back restores no filter
+	updatedModel, _ = m.updateListMode(backspace)
+	m = updatedModel.(model)
+
+	if m.filterStatus != nil || m.filterCategory != nil {
+		t.Error("Expected no filters after stepping back twice")
+	}
+	if len(m.tasks) != 2 {
+		t.Errorf("Expected all 2 tasks, got %d", len(m.tasks))
+	}
+
+	// Nothing left to restore
+	updatedModel, _ = m.updateListMode(backspace)
+	m = updatedModel.(model)
+
+	if !contains(m.message, "No previous filter") {
+		t.Errorf("Expected empty-history message, got %q", m.message)
+	}
+}
+
+func TestModel_FilterHistory_Capped(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for i := 0; i < maxFilterHistory+5; i++ {
+		m.applyStatusFilter(StatusDone, "")
+	}
+
+	if len(m.filterHistory) != maxFilterHistory {
+		t.Errorf("Expected history capped at %d, got %d", maxFilterHistory, len(m.filterHistory))
+	}
+}
+
 func TestModel_View(t *testing.T) {
 	m, tmpDir := createTestModel(t)
 	defer func() { _ = os.RemoveAll(tmpDir) }()
@@ -548,6 +627,32 @@ func TestModel_View_WithTasks(t *testing.T) {
 	}
 }
 
+func TestModel_View_FilteredEmpty(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// Empty store shows the create hint
+	view := m.View()
+	if !contains(view, "No tasks yet") {
+		t.Error("Empty store should show the create hint")
+	}
+
+	if err := m.store.Add("Task 1", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	status := StatusDone
+	m.filterStatus = &status
+	m.refreshTasks()
+
+	view = m.View()
+	if contains(view, "No tasks yet") {
+		t.Error("Filtered-empty view should not claim there are no tasks")
+	}
+	if !contains(view, "No tasks match the current filter") {
+		t.Error("Filtered-empty view should explain that the filter hides tasks")
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsMiddle(s, substr)))
 }
@@ -1323,3 +1428,3092 @@ func TestModel_View_HelpTextWithViewToggle(t *testing.T) {
 		t.Error("Help text should show current view style (list)")
 	}
 }
+
+func TestModel_TaskCountWarning(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	m.config.TaskWarningThreshold = 2
+	for _, desc := range []string{"Task 1", "Task 2"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+
+	// At the threshold there is no warning
+	if contains(m.View(), "consider archiving") {
+		t.Error("Warning should not show at the threshold")
+	}
+
+	if err := m.store.Add("Task 3", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	if !contains(m.View(), "consider archiving") {
+		t.Error("Warning should show above the threshold")
+	}
+
+	// Dismissing hides it for the rest of the session
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	m = updatedModel.(model)
+
+	if contains(m.View(), "consider archiving") {
+		t.Error("Warning should be hidden after dismissal")
+	}
+}
+
+func TestModel_Rename_Save(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Tpyo task", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = updatedModel.(model)
+
+	if m.viewMode != ModeRename {
+		t.Fatalf("Expected ModeRename, got %d", m.viewMode)
+	}
+	if m.textInput.Value() != "Tpyo task" {
+		t.Errorf("Expected input prefilled with description, got '%s'", m.textInput.Value())
+	}
+
+	m.textInput.SetValue("Typo task")
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+
+	if m.viewMode != ModeList {
+		t.Errorf("Expected to return to list mode, got %d", m.viewMode)
+	}
+	task := m.store.GetAll()[0]
+	if task.Description != "Typo task" {
+		t.Errorf("Expected description 'Typo task', got '%s'", task.Description)
+	}
+	if task.Category != "work" {
+		t.Errorf("Expected category unchanged, got '%s'", task.Category)
+	}
+}
+
+func TestModel_Rename_Cancel(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Original", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = updatedModel.(model)
+	m.textInput.SetValue("Changed")
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updatedModel.(model)
+
+	if m.viewMode != ModeList {
+		t.Errorf("Expected to return to list mode, got %d", m.viewMode)
+	}
+	if m.store.GetAll()[0].Description != "Original" {
+		t.Errorf("Expected description unchanged, got '%s'", m.store.GetAll()[0].Description)
+	}
+	if m.editingTaskID != "" {
+		t.Error("Expected editingTaskID cleared after cancel")
+	}
+}
+
+func TestModel_Welcome_FirstRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := &TaskStore{
+		filepath: filepath.Join(tmpDir, "tasks.json"),
+		tasks:    []Task{},
+		firstRun: true,
+	}
+	cfg, err := loadConfigFile(filepath.Join(tmpDir, "config.json"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	m := initialModel(store, cfg)
+	if !m.showWelcome {
+		t.Fatal("Expected welcome panel on first run")
+	}
+	if !contains(m.View(), "Welcome to patodo") {
+		t.Error("Expected view to contain welcome panel")
+	}
+
+	// Any key dismisses it without acting on the key
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updatedModel.(model)
+
+	if m.showWelcome {
+		t.Error("Expected welcome panel to be dismissed")
+	}
+	if m.viewMode != ModeList {
+		t.Errorf("Dismissing key should not change mode, got %d", m.viewMode)
+	}
+
+	// The dismissal is remembered in the config
+	reloaded, err := loadConfigFile(filepath.Join(tmpDir, "config.json"))
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if !reloaded.OnboardingDone {
+		t.Error("Expected onboarding to be recorded in config")
+	}
+	if initialModel(store, reloaded).showWelcome {
+		t.Error("Welcome panel should not show again once dismissed")
+	}
+}
+
+func TestModel_Welcome_NotFirstRun(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if m.showWelcome {
+		t.Error("Welcome panel should not show when a tasks file exists")
+	}
+}
+
+func TestModel_GroupByStatus(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Pending A", "Done B", "Working C", "Pending D"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := m.store.GetAll()
+	if err := m.store.UpdateStatus(tasks[1].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if err := m.store.UpdateStatus(tasks[2].ID, StatusInProgress); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	m.refreshTasks()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	m = updatedModel.(model)
+
+	// Cursor order follows display order: in-progress, pending, done
+	want := []string{"Working C", "Pending A", "Pending D", "Done B"}
+	for i, desc := range want {
+		if m.cursor != i {
+			t.Fatalf("Expected cursor at %d, got %d", i, m.cursor)
+		}
+		if m.getCurrentTask().Description != desc {
+			t.Errorf("Expected '%s' at position %d, got '%s'", desc, i, m.getCurrentTask().Description)
+		}
+		updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+		m = updatedModel.(model)
+	}
+
+	view := m.View()
+	inProgress := strings.Index(view, "In Progress")
+	pending := strings.Index(view, "Pending\n")
+	done := strings.Index(view, "Done\n")
+	if inProgress == -1 || pending == -1 || done == -1 {
+		t.Fatalf("Expected all group headers in view:\n%s", view)
+	}
+	if !(inProgress < pending && pending < done) {
+		t.Error("Expected groups in order In Progress, Pending, Done")
+	}
+}
+
+func TestModel_GroupByStatus_OmitsEmptyGroups(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Only pending", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.groupByStatus = true
+	m.refreshTasks()
+
+	view := m.View()
+	if contains(view, "In Progress") {
+		t.Error("Empty in-progress group should not render a header")
+	}
+	if !contains(view, "Pending") {
+		t.Error("Pending group header should render")
+	}
+}
+
+func TestModel_DiscardQuit(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	updatedModel, cmd := m.updateListMode(tea.KeyMsg{Type: tea.KeyCtrlX})
+	m = updatedModel.(model)
+
+	if !m.quitting || !m.discarded {
+		t.Error("ctrl+x should quit and mark the session discarded")
+	}
+	if cmd == nil {
+		t.Error("ctrl+x should return the quit command")
+	}
+	if _, err := os.Stat(m.store.filepath); !os.IsNotExist(err) {
+		t.Error("Discard-quit should not save the store")
+	}
+	if !contains(m.View(), "discarded") {
+		t.Error("Goodbye message should mention discarded changes")
+	}
+}
+
+func TestModel_TogglePin(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Task 1", "Task 2", "Task 3"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+
+	// Pin the last task
+	m.cursor = 2
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'*'}})
+	m = updatedModel.(model)
+
+	if !m.store.GetAll()[2].Pinned {
+		t.Fatal("Expected task to be pinned in the store")
+	}
+	if m.tasks[0].Description != "Task 3" {
+		t.Errorf("Expected pinned task first, got '%s'", m.tasks[0].Description)
+	}
+	if m.cursor != 0 {
+		t.Errorf("Expected cursor to follow the pinned task, got %d", m.cursor)
+	}
+	if !contains(m.View(), "📌") {
+		t.Error("Expected pin marker in view")
+	}
+
+	// Unpin restores insertion order
+	updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'*'}})
+	m = updatedModel.(model)
+
+	if m.store.GetAll()[2].Pinned {
+		t.Error("Expected task to be unpinned")
+	}
+	if m.tasks[2].Description != "Task 3" {
+		t.Errorf("Expected unpinned task back in place, got '%s'", m.tasks[2].Description)
+	}
+}
+
+func TestModel_PinnedFirstWithGrouping(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Done pinned", "Working", "Pending pinned", "Pending"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := m.store.GetAll()
+	for _, id := range []string{tasks[0].ID, tasks[2].ID} {
+		if err := m.store.TogglePin(id); err != nil {
+			t.Fatalf("Failed to pin task: %v", err)
+		}
+	}
+	if err := m.store.UpdateStatus(tasks[0].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if err := m.store.UpdateStatus(tasks[1].ID, StatusInProgress); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	m.groupByStatus = true
+	m.refreshTasks()
+
+	// Pinned tasks come first, sorted by group among themselves
+	want := []string{"Pending pinned", "Done pinned", "Working", "Pending"}
+	for i, desc := range want {
+		if m.tasks[i].Description != desc {
+			t.Errorf("Position %d: expected '%s', got '%s'", i, desc, m.tasks[i].Description)
+		}
+	}
+}
+
+func TestModel_CategoryColor(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	m.config.CategoryColors = map[string]string{
+		"work":     "blue",
+		"personal": "#00aa55",
+		"errands":  "208",
+	}
+
+	tests := []struct {
+		category TaskCategory
+		want     string
+	}{
+		{"work", "4"},
+		{"personal", "#00aa55"},
+		{"errands", "208"},
+		{"unmapped", colorCategory},
+		{"", colorCategory},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.category), func(t *testing.T) {
+			if got := m.categoryColor(tt.category); got != tt.want {
+				t.Errorf("categoryColor(%q) = %q, want %q", tt.category, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel_CycleCategory(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// A second "work" task keeps that category around while the first moves
+	for _, category := range []TaskCategory{"work", "home", "errands", "work"} {
+		if err := m.store.Add("Task "+string(category), category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+	id := m.tasks[0].ID // starts in "work"
+
+	next := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{']'}}
+	prev := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'['}}
+
+	// Sorted order is errands, home, work; "]" from work wraps to errands
+	for _, want := range []TaskCategory{"errands", "home", "work"} {
+		updatedModel, _ := m.updateListMode(next)
+		m = updatedModel.(model)
+
+		task, _ := m.store.Get(id)
+		if task.Category != want {
+			t.Errorf("Expected category '%s', got '%s'", want, task.Category)
+		}
+		if !contains(m.message, string(want)) {
+			t.Errorf("Expected message to show '%s', got %q", want, m.message)
+		}
+	}
+
+	// "[" from work goes back to home
+	updatedModel, _ := m.updateListMode(prev)
+	m = updatedModel.(model)
+	if task, _ := m.store.Get(id); task.Category != "home" {
+		t.Errorf("Expected category 'home', got '%s'", task.Category)
+	}
+}
+
+func TestModel_ToggleBlocked(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Ship release", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	m = updatedModel.(model)
+	if m.viewMode != ModeBlockReason {
+		t.Fatalf("Expected ModeBlockReason, got %d", m.viewMode)
+	}
+
+	m.textInput.SetValue("QA sign-off")
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+
+	task := m.store.GetAll()[0]
+	if !task.Blocked || task.BlockedReason != "QA sign-off" {
+		t.Errorf("Expected task blocked on 'QA sign-off', got %+v", task)
+	}
+	m.viewAsTable = false
+	view := m.View()
+	if !contains(view, "⏸") || !contains(view, "QA sign-off") {
+		t.Error("Expected blocked marker and reason in list view")
+	}
+
+	// Pressing b again unblocks immediately
+	updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	m = updatedModel.(model)
+	if m.store.GetAll()[0].Blocked {
+		t.Error("Expected task to be unblocked")
+	}
+	if m.viewMode != ModeList {
+		t.Errorf("Unblocking should stay in list mode, got %d", m.viewMode)
+	}
+}
+
+func TestModel_ActionableFilterExcludesBlocked(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Open", "Waiting"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := m.store.SetBlocked(m.store.GetAll()[1].ID, true, ""); err != nil {
+		t.Fatalf("Failed to block task: %v", err)
+	}
+
+	m.viewMode = ModeFilter
+	updatedModel, _ := m.updateFilterMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m = updatedModel.(model)
+
+	if len(m.tasks) != 1 || m.tasks[0].Description != "Open" {
+		t.Errorf("Expected only the open task, got %+v", m.tasks)
+	}
+	if m.filterInfo() != "actionable" {
+		t.Errorf("Expected filter info 'actionable', got '%s'", m.filterInfo())
+	}
+}
+
+func TestModel_SpaceMarksTasks(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"One", "Two"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updatedModel.(model)
+	if !m.marked[m.tasks[0].ID] {
+		t.Error("Expected first task to be selected")
+	}
+	if m.cursor != 1 {
+		t.Errorf("Expected cursor to advance to 1, got %d", m.cursor)
+	}
+
+	m.cursor = 0
+	updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updatedModel.(model)
+	if len(m.marked) != 0 {
+		t.Errorf("Expected selection to be toggled off, got %v", m.marked)
+	}
+}
+
+func TestModel_BulkDeleteConfirmThreshold(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"One", "Two", "Three"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+	m.config.ConfirmThreshold = 2
+
+	// Exactly the threshold deletes without asking
+	m.marked = map[string]bool{m.tasks[0].ID: true, m.tasks[1].ID: true}
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updatedModel.(model)
+	if m.viewMode != ModeList {
+		t.Fatalf("Expected no confirmation at the threshold, got mode %d", m.viewMode)
+	}
+	if len(m.store.GetAll()) != 1 {
+		t.Errorf("Expected 1 task left, got %d", len(m.store.GetAll()))
+	}
+	if len(m.marked) != 0 {
+		t.Error("Expected selection to be cleared after delete")
+	}
+}
+
+func TestModel_BulkDeleteConfirm(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"One", "Two", "Three"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+	m.config.ConfirmThreshold = 1
+
+	selectAll := func() {
+		m.marked = map[string]bool{}
+		for _, task := range m.tasks {
+			m.marked[task.ID] = true
+		}
+	}
+
+	// Answering n keeps every task
+	selectAll()
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updatedModel.(model)
+	if m.viewMode != ModeConfirm {
+		t.Fatalf("Expected confirm mode, got %d", m.viewMode)
+	}
+	if !contains(m.message, "Delete 3 tasks?") {
+		t.Errorf("Expected prompt with count, got %q", m.message)
+	}
+	updatedModel, _ = m.updateConfirmMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updatedModel.(model)
+	if m.viewMode != ModeList || len(m.store.GetAll()) != 3 {
+		t.Fatalf("Expected cancel to keep all tasks, got mode %d and %d tasks", m.viewMode, len(m.store.GetAll()))
+	}
+
+	// Answering y deletes them all
+	selectAll()
+	updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updatedModel.(model)
+	updatedModel, _ = m.updateConfirmMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updatedModel.(model)
+	if len(m.store.GetAll()) != 0 {
+		t.Errorf("Expected all tasks deleted, got %d", len(m.store.GetAll()))
+	}
+	if m.message != "3 tasks deleted" {
+		t.Errorf("Expected '3 tasks deleted', got %q", m.message)
+	}
+}
+
+func TestModel_ReloadFromDisk(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"One", "Two"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+	m.cursor = 1
+	current := m.tasks[1]
+
+	// Simulate an external edit that prepends a task
+	external := append([]Task{newTask("Zero", "work")}, m.store.GetAll()...)
+	if err := writeTasksFile(m.store.filepath, external, false); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updatedModel.(model)
+	if len(m.tasks) != 3 {
+		t.Fatalf("Expected 3 tasks after reload, got %d", len(m.tasks))
+	}
+	if m.message != "Reloaded 3 tasks" {
+		t.Errorf("Expected reload message, got %q", m.message)
+	}
+	if m.tasks[m.cursor].ID != current.ID {
+		t.Errorf("Expected cursor to stay on '%s', got '%s'", current.Description, m.tasks[m.cursor].Description)
+	}
+
+	// A broken file keeps the in-memory state
+	if err := os.WriteFile(m.store.filepath, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+	updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updatedModel.(model)
+	if !contains(m.message, "Error reloading tasks") {
+		t.Errorf("Expected reload error message, got %q", m.message)
+	}
+	if len(m.store.GetAll()) != 3 {
+		t.Errorf("Expected in-memory tasks to be kept, got %d", len(m.store.GetAll()))
+	}
+}
+
+func TestModel_TableCreatedColumn(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Triage me", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	task := m.store.GetAll()[0]
+	task.CreatedAt = time.Now().Add(-3 * 24 * time.Hour)
+	m.store.tasks[0] = task
+	m.refreshTasks()
+	m.viewAsTable = true
+
+	if contains(m.View(), "Created") {
+		t.Error("Expected no Created column by default")
+	}
+
+	m.config.ShowCreatedColumn = true
+	view := m.View()
+	if !contains(view, "Created") {
+		t.Error("Expected Created column header when enabled")
+	}
+	if want := humanizeTime(task.CreatedAt, time.Now()); !contains(view, want) || want != "3d ago" {
+		t.Errorf("Expected row to show %q, got:\n%s", want, view)
+	}
+
+	// Narrow terminals drop the column
+	updatedModel, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = updatedModel.(model)
+	if contains(m.View(), "Created") {
+		t.Error("Expected Created column to be dropped on a narrow terminal")
+	}
+}
+
+func TestModel_FilterBuilder(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Report", "Slides", "Groceries"} {
+		category := TaskCategory("work")
+		if desc == "Groceries" {
+			category = "home"
+		}
+		if err := m.store.Add(desc, category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := m.store.GetAll()
+	if err := m.store.UpdateStatus(tasks[1].ID, StatusInProgress); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	m.refreshTasks()
+
+	press := func(key tea.KeyMsg) {
+		t.Helper()
+		updatedModel, _ := m.Update(key)
+		m = updatedModel.(model)
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	press(runes("f"))
+	press(runes("b"))
+	if m.viewMode != ModeFilterBuilder {
+		t.Fatalf("Expected filter builder mode, got %d", m.viewMode)
+	}
+
+	// Status: any -> pending; category: any -> home -> work
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	if !contains(m.View(), "pending") || !contains(m.View(), "work") {
+		t.Errorf("Expected draft filter to be rendered, got:\n%s", m.View())
+	}
+	if len(m.tasks) != 3 {
+		t.Error("Expected filters not to apply before Enter")
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.viewMode != ModeList {
+		t.Fatalf("Expected list mode after applying, got %d", m.viewMode)
+	}
+	if len(m.tasks) != 1 || m.tasks[0].Description != "Report" {
+		t.Errorf("Expected only the pending work task, got %+v", m.tasks)
+	}
+	if m.filterInfo() != "pending + work" {
+		t.Errorf("Expected filter info 'pending + work', got '%s'", m.filterInfo())
+	}
+
+	// Esc leaves the active filters untouched
+	press(runes("f"))
+	press(runes("b"))
+	press(tea.KeyMsg{Type: tea.KeyLeft})
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.filterInfo() != "pending + work" {
+		t.Errorf("Expected cancel to keep filters, got '%s'", m.filterInfo())
+	}
+}
+
+func TestModel_FilterDueWindow(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Late", "Today", "Later", "Undated"} {
+		if err := m.store.Add(desc, ""); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	today := startOfDay(time.Now())
+	tasks := m.store.GetAll()
+	err := m.store.Batch(func(b *TaskBatch) error {
+		late, noon, later := today.AddDate(0, 0, -2), today.Add(12*time.Hour), today.AddDate(0, 0, 3)
+		b.SetDueDate(tasks[0].ID, &late)
+		b.SetDueDate(tasks[1].ID, &noon)
+		b.SetDueDate(tasks[2].ID, &later)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to set due dates: %v", err)
+	}
+
+	tests := []struct {
+		due  dueWindow
+		want int
+	}{
+		{DueAny, 4},
+		{DueOverdue, 1},
+		{DueToday, 2},
+		{DueThisWeek, 3},
+	}
+	for _, tt := range tests {
+		m.setFilter(filterState{due: tt.due})
+		m.refreshTasks()
+		if len(m.tasks) != tt.want {
+			t.Errorf("%s: expected %d tasks, got %d", tt.due, tt.want, len(m.tasks))
+		}
+	}
+}
+
+func TestModel_CompletionBell(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Ring me", ""); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+	done := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}}
+
+	// Disabled by default
+	updatedModel, cmd := m.updateListMode(done)
+	m = updatedModel.(model)
+	if cmd != nil {
+		t.Error("Expected no bell when the completion bell is disabled")
+	}
+	updatedModel, _ = m.updateListMode(done)
+	m = updatedModel.(model)
+
+	m.config.CompletionBell = true
+	var output strings.Builder
+	m.output = &output
+
+	// pending -> done rings, on the program's output
+	updatedModel, cmd = m.updateListMode(done)
+	m = updatedModel.(model)
+	if cmd == nil {
+		t.Fatal("Expected bell command on pending -> done")
+	}
+	cmd()
+	if output.String() != "\a" {
+		t.Errorf("Expected the bell written to the output, got %q", output.String())
+	}
+
+	// done -> pending stays quiet
+	updatedModel, cmd = m.updateListMode(done)
+	m = updatedModel.(model)
+	if cmd != nil {
+		t.Error("Expected no bell on done -> pending")
+	}
+	if m.tasks[0].Status != StatusPending {
+		t.Fatalf("Expected task back to pending, got %s", m.tasks[0].Status)
+	}
+
+	// Other transitions stay quiet
+	_, cmd = m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	if cmd != nil {
+		t.Error("Expected no bell on pending -> in-progress")
+	}
+}
+
+func TestRingsBell(t *testing.T) {
+	tests := []struct {
+		from, to TaskStatus
+		want     bool
+	}{
+		{StatusPending, StatusDone, true},
+		{StatusInProgress, StatusDone, false},
+		{StatusDone, StatusDone, false},
+		{StatusDone, StatusPending, false},
+		{StatusPending, StatusInProgress, false},
+	}
+	for _, tt := range tests {
+		if got := ringsBell(tt.from, tt.to); got != tt.want {
+			t.Errorf("ringsBell(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestModel_JumpToStatus(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"A", "B", "C", "D", "E"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := m.store.GetAll()
+	for _, i := range []int{1, 3} {
+		if err := m.store.UpdateStatus(tasks[i].ID, StatusInProgress); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+	}
+	m.refreshTasks()
+	m.config.JumpStatus = StatusInProgress
+
+	press := func(key string) {
+		updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = updatedModel.(model)
+	}
+
+	// Forward: 0 -> 1 -> 3 -> wraps to 1
+	for _, want := range []int{1, 3, 1} {
+		press("}")
+		if m.cursor != want {
+			t.Fatalf("Expected cursor %d after }, got %d", want, m.cursor)
+		}
+	}
+
+	// Backward from 1 wraps to 3, then 1
+	for _, want := range []int{3, 1} {
+		press("{")
+		if m.cursor != want {
+			t.Fatalf("Expected cursor %d after {, got %d", want, m.cursor)
+		}
+	}
+
+	m.config.JumpStatus = StatusDone
+	press("}")
+	if m.cursor != 1 {
+		t.Errorf("Expected cursor to stay at 1, got %d", m.cursor)
+	}
+	if m.message != "No done tasks in view" {
+		t.Errorf("Expected no-match message, got %q", m.message)
+	}
+}
+
+func TestModel_DueSoonBanner(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Due in 12h", "Due in 36h", "Already overdue", "Done soon"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	now := time.Now()
+	tasks := m.store.GetAll()
+	err := m.store.Batch(func(b *TaskBatch) error {
+		soon, later, past := now.Add(12*time.Hour), now.Add(36*time.Hour), now.Add(-2*time.Hour)
+		b.SetDueDate(tasks[0].ID, &soon)
+		b.SetDueDate(tasks[1].ID, &later)
+		b.SetDueDate(tasks[2].ID, &past)
+		b.SetDueDate(tasks[3].ID, &soon)
+		b.UpdateStatus(tasks[3].ID, StatusDone)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to set due dates: %v", err)
+	}
+
+	m.config.DueSoonHours = 24
+	m.refreshTasks()
+
+	if len(m.dueSoon) != 1 || m.dueSoon[0].Description != "Due in 12h" {
+		t.Fatalf("Expected only the task due in 12h, got %+v", m.dueSoon)
+	}
+	view := m.View()
+	if !contains(view, "Due in the next 24h") {
+		t.Error("Expected due-soon banner in view")
+	}
+	if !contains(view, "• Due in 12h") {
+		t.Error("Expected due-soon task listed in banner")
+	}
+	for _, desc := range []string{"• Due in 36h", "• Already overdue", "• Done soon"} {
+		if contains(view, desc) {
+			t.Errorf("Expected %q not to be listed in banner", desc)
+		}
+	}
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	m = updatedModel.(model)
+	if contains(m.View(), "Due in the next") {
+		t.Error("Expected banner to be dismissed")
+	}
+}
+
+func TestModel_SearchAllFields(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Write report", "Call accountant", "Book flights"} {
+		category := TaskCategory("work")
+		if desc == "Book flights" {
+			category = "travel"
+		}
+		if err := m.store.Add(desc, category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := m.store.UpdateNotes(m.store.GetAll()[1].ID, "Bring the travel receipts"); err != nil {
+		t.Fatalf("Failed to update notes: %v", err)
+	}
+	m.refreshTasks()
+
+	search := func(query string) {
+		t.Helper()
+		updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+		m = updatedModel.(model)
+		if m.viewMode != ModeSearch {
+			t.Fatalf("Expected search mode, got %d", m.viewMode)
+		}
+		m.textInput.SetValue(query)
+		updatedModel, _ = m.updateSearchMode(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updatedModel.(model)
+	}
+
+	// Description only by default
+	search("travel")
+	if len(m.tasks) != 0 {
+		t.Errorf("Expected no description matches, got %+v", m.tasks)
+	}
+	search("REPORT")
+	if len(m.tasks) != 1 || m.tasks[0].Description != "Write report" {
+		t.Errorf("Expected description match, got %+v", m.tasks)
+	}
+
+	// Tab switches to all fields: notes and category match too
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	m = updatedModel.(model)
+	updatedModel, _ = m.updateSearchMode(tea.KeyMsg{Type: tea.KeyTab})
+	m = updatedModel.(model)
+	if !contains(m.message, "all fields") {
+		t.Errorf("Expected all-fields hint, got %q", m.message)
+	}
+	m.textInput.SetValue("travel")
+	updatedModel, _ = m.updateSearchMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+	if len(m.tasks) != 2 {
+		t.Fatalf("Expected notes and category matches, got %+v", m.tasks)
+	}
+	if !contains(m.View(), "notes: Bring the travel receipts") {
+		t.Error("Expected notes match to be shown in the list")
+	}
+
+	// The detail view says which field matched
+	wantFields := map[string]string{"Call accountant": "notes", "Book flights": "category"}
+	for i, task := range m.tasks {
+		m.cursor = i
+		updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updatedModel.(model)
+		if m.viewMode != ModeDetail {
+			t.Fatalf("Expected detail mode, got %d", m.viewMode)
+		}
+		if view := m.View(); !contains(view, "Matched in:  "+wantFields[task.Description]) {
+			t.Errorf("Expected %s match for '%s', got:\n%s", wantFields[task.Description], task.Description, view)
+		}
+		updatedModel, _ = m.updateDetailMode(tea.KeyMsg{Type: tea.KeyEsc})
+		m = updatedModel.(model)
+	}
+
+	// Esc in search mode clears the search
+	updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	m = updatedModel.(model)
+	updatedModel, _ = m.updateSearchMode(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updatedModel.(model)
+	if len(m.tasks) != 3 || m.searchQuery != "" {
+		t.Errorf("Expected search to be cleared, got %d tasks and query %q", len(m.tasks), m.searchQuery)
+	}
+}
+
+func TestModel_EditNotes(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	m = updatedModel.(model)
+	if m.viewMode != ModeNotes {
+		t.Fatalf("Expected notes mode, got %d", m.viewMode)
+	}
+	m.textInput.SetValue("  Use the new template  ")
+	updatedModel, _ = m.updateNotesMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+
+	if notes := m.store.GetAll()[0].Notes; notes != "Use the new template" {
+		t.Errorf("Expected trimmed notes, got %q", notes)
+	}
+}
+
+func TestModel_EscClearsMessage(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	m.message = "Task created: Write report [work]"
+	updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updatedModel.(model)
+	if m.message != "" {
+		t.Errorf("Expected message to be cleared, got %q", m.message)
+	}
+	if m.viewMode != ModeList {
+		t.Errorf("Expected to stay in list mode, got %d", m.viewMode)
+	}
+
+	// Esc in other modes keeps its own meaning
+	m.viewMode = ModeFilter
+	updatedModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updatedModel.(model)
+	if m.viewMode != ModeList || m.message != "Filter cancelled" {
+		t.Errorf("Expected filter cancel, got mode %d and message %q", m.viewMode, m.message)
+	}
+}
+
+func TestModel_CreateDuplicateNeedsConfirmation(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Buy milk", "home"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	create := func() {
+		t.Helper()
+		updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+		m = updatedModel.(model)
+		m.textInput.SetValue("buy Milk")
+		m.categoryInput.SetValue("home")
+		updatedModel, _ = m.updateCreateMode(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updatedModel.(model)
+	}
+
+	create()
+	if m.viewMode != ModeCreate {
+		t.Fatalf("Expected to stay in create mode, got %d", m.viewMode)
+	}
+	if !contains(m.message, "Similar task exists") {
+		t.Errorf("Expected duplicate warning, got %q", m.message)
+	}
+	if len(m.store.GetAll()) != 1 {
+		t.Fatalf("Expected no task to be created yet, got %d", len(m.store.GetAll()))
+	}
+
+	// Second Enter creates it anyway
+	updatedModel, _ := m.updateCreateMode(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+	if len(m.store.GetAll()) != 2 || m.viewMode != ModeList {
+		t.Errorf("Expected duplicate to be created on confirmation, got %d tasks", len(m.store.GetAll()))
+	}
+
+	// Skipping the check creates right away
+	m.config.SkipDuplicateCheck = true
+	create()
+	if len(m.store.GetAll()) != 3 {
+		t.Errorf("Expected task to be created without warning, got %d tasks", len(m.store.GetAll()))
+	}
+}
+
+func TestModel_CompleteCategory(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, category := range []TaskCategory{"work", "home", "work"} {
+		if err := m.store.Add("Task", category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	m = updatedModel.(model)
+	if m.viewMode != ModeConfirm {
+		t.Fatalf("Expected confirm mode, got %d", m.viewMode)
+	}
+	if !contains(m.message, "Mark 2 tasks in work as done?") {
+		t.Errorf("Expected prompt with count, got %q", m.message)
+	}
+
+	updatedModel, _ = m.updateConfirmMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updatedModel.(model)
+	if m.message != "Marked 2 tasks in work as done" {
+		t.Errorf("Expected completion message, got %q", m.message)
+	}
+	for _, task := range m.store.GetAll() {
+		if want := map[TaskCategory]TaskStatus{"work": StatusDone, "home": StatusPending}[task.Category]; task.Status != want {
+			t.Errorf("Expected %s task to be %s, got %s", task.Category, want, task.Status)
+		}
+	}
+}
+
+func TestModel_InputOverflowIndicator(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updatedModel.(model)
+
+	m.textInput.SetValue(strings.Repeat("a", m.textInput.Width))
+	m.categoryInput.SetValue("work")
+	if contains(m.View(), "…") {
+		t.Error("Expected no overflow indicator when the text fits")
+	}
+
+	m.textInput.SetValue(strings.Repeat("a", m.textInput.Width+10))
+	if !contains(m.View(), fmt.Sprintf("… (%d chars)", m.textInput.Width+10)) {
+		t.Error("Expected overflow indicator for the description")
+	}
+
+	// The category's character limit matches its width by default
+	m.textInput.SetValue("short")
+	m.categoryInput.Width = 10
+	m.categoryInput.SetValue(strings.Repeat("c", m.categoryInput.Width+1))
+	if !contains(m.View(), fmt.Sprintf("… (%d chars)", m.categoryInput.Width+1)) {
+		t.Error("Expected overflow indicator for the category")
+	}
+}
+
+func TestInitialModel_DefaultSort(t *testing.T) {
+	store := setupTestStore(t)
+	defer cleanupTestStore(store)
+
+	for _, desc := range []string{"Later", "Undated", "Soon"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := store.GetAll()
+	err := store.Batch(func(b *TaskBatch) error {
+		soon, later := time.Now().AddDate(0, 0, 1), time.Now().AddDate(0, 0, 5)
+		b.SetDueDate(tasks[0].ID, &later)
+		b.SetDueDate(tasks[2].ID, &soon)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to set due dates: %v", err)
+	}
+
+	order := func(m model) []string {
+		var descs []string
+		for _, task := range m.tasks {
+			descs = append(descs, task.Description)
+		}
+		return descs
+	}
+
+	cfg := DefaultConfig()
+	cfg.DefaultSort = "due"
+	if got := order(initialModel(store, cfg)); !slices.Equal(got, []string{"Soon", "Later", "Undated"}) {
+		t.Errorf("Expected due date order, got %v", got)
+	}
+
+	cfg.DefaultSortReverse = true
+	if got := order(initialModel(store, cfg)); !slices.Equal(got, []string{"Later", "Soon", "Undated"}) {
+		t.Errorf("Expected reverse due date order with undated last, got %v", got)
+	}
+
+	cfg.DefaultSort = "description"
+	if got := order(initialModel(store, cfg)); !slices.Equal(got, []string{"Undated", "Soon", "Later"}) {
+		t.Errorf("Expected reverse description order, got %v", got)
+	}
+
+	cfg.DefaultSort = "priority"
+	m := initialModel(store, cfg)
+	if got := order(m); !slices.Equal(got, []string{"Later", "Undated", "Soon"}) {
+		t.Errorf("Expected insertion order for an unknown sort, got %v", got)
+	}
+	if !contains(m.message, `Unknown default_sort "priority"`) {
+		t.Errorf("Expected warning about the unknown sort, got %q", m.message)
+	}
+}
+
+func TestModel_IsStalledInProgress(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	m.config.StalledDays = 3
+
+	now := time.Now()
+	tests := []struct {
+		name    string
+		status  TaskStatus
+		updated time.Time
+		want    bool
+	}{
+		{"just under threshold", StatusInProgress, now.AddDate(0, 0, -3).Add(time.Minute), false},
+		{"just over threshold", StatusInProgress, now.AddDate(0, 0, -3).Add(-time.Minute), true},
+		{"pending is exempt", StatusPending, now.AddDate(0, 0, -10), false},
+		{"done is exempt", StatusDone, now.AddDate(0, 0, -10), false},
+	}
+	for _, tt := range tests {
+		task := Task{Status: tt.status, UpdatedAt: tt.updated}
+		if got := m.isStalledInProgress(task); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	m.config.StalledDays = 0
+	if m.isStalledInProgress(Task{Status: StatusInProgress, UpdatedAt: now.AddDate(-1, 0, 0)}) {
+		t.Error("Expected no stalled tasks when disabled")
+	}
+}
+
+func TestModel_StalledMarkerAndFilter(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	m.config.StalledDays = 3
+
+	for _, desc := range []string{"Old work", "Fresh work"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	for _, task := range m.store.GetAll() {
+		if err := m.store.UpdateStatus(task.ID, StatusInProgress); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+	}
+	m.store.tasks[0].UpdatedAt = time.Now().AddDate(0, 0, -5)
+	m.refreshTasks()
+
+	if view := m.View(); !contains(view, "⌛ Old work") || contains(view, "⌛ Fresh work") {
+		t.Errorf("Expected only the old task to be marked stalled, got:\n%s", view)
+	}
+
+	m.viewMode = ModeFilter
+	updatedModel, _ := m.updateFilterMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = updatedModel.(model)
+	if len(m.tasks) != 1 || m.tasks[0].Description != "Old work" {
+		t.Errorf("Expected only the stalled task, got %+v", m.tasks)
+	}
+	if m.filterInfo() != "stalled" {
+		t.Errorf("Expected filter info 'stalled', got '%s'", m.filterInfo())
+	}
+}
+
+func TestModel_ToggleHelp(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	m.config = DefaultConfig()
+	m.config.path = filepath.Join(tmpDir, "config.json")
+
+	if !contains(m.View(), "[n] new task") {
+		t.Fatal("Expected full help by default")
+	}
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	m = updatedModel.(model)
+	view := m.View()
+	if contains(view, "[n] new task") {
+		t.Error("Expected full help to be hidden")
+	}
+	if !contains(view, "? for help") {
+		t.Error("Expected one-line help hint")
+	}
+
+	saved, err := loadConfigFile(m.config.path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if saved.ShowHelp {
+		t.Error("Expected hidden help to be persisted")
+	}
+
+	updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	m = updatedModel.(model)
+	if !contains(m.View(), "[n] new task") {
+		t.Error("Expected full help to be shown again")
+	}
+}
+
+func TestModel_RecentlyUpdatedFilter(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Old task", "Fresh task"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.store.tasks[0].UpdatedAt = time.Now().AddDate(0, 0, -8)
+	m.refreshTasks()
+
+	m.viewMode = ModeFilter
+	updatedModel, _ := m.updateFilterMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	m = updatedModel.(model)
+	if len(m.tasks) != 1 || m.tasks[0].Description != "Fresh task" {
+		t.Errorf("Expected only the fresh task, got %+v", m.tasks)
+	}
+	if m.filterInfo() != "updated this week" {
+		t.Errorf("Expected filter info 'updated this week', got '%s'", m.filterInfo())
+	}
+
+	if !m.popFilterHistory() {
+		t.Fatal("Expected a previous filter to restore")
+	}
+	if len(m.tasks) != 2 {
+		t.Errorf("Expected both tasks after restoring the filter, got %d", len(m.tasks))
+	}
+}
+
+func TestModel_CycleStatusFilter(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	want := []string{"pending", "in-progress", "done", "all", "pending"}
+	for _, info := range want {
+		updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+		m = updatedModel.(model)
+		if got := m.filterInfo(); got != info {
+			t.Fatalf("Expected filter %q, got %q", info, got)
+		}
+	}
+
+	if view := m.View(); !contains(view, "📝 patodo · pending") {
+		t.Errorf("Expected the header to show the status filter, got:\n%s", view)
+	}
+}
+
+func TestModel_RestoreLastTask(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := &TaskStore{filepath: filepath.Join(tmpDir, "tasks.json"), tasks: []Task{}}
+	for _, desc := range []string{"First", "Second", "Third"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	cfg, err := loadConfigFile(filepath.Join(tmpDir, "config.json"))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Quitting on a task records its ID
+	m := initialModel(store, cfg)
+	m.cursor = 2
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	m = updatedModel.(model)
+
+	reloaded, err := loadConfigFile(filepath.Join(tmpDir, "config.json"))
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if reloaded.LastTaskID != store.tasks[2].ID {
+		t.Fatalf("Expected last task ID %q, got %q", store.tasks[2].ID, reloaded.LastTaskID)
+	}
+
+	// Restore to an existing task
+	m = initialModel(store, reloaded)
+	if m.cursor != 2 || m.getCurrentTask().Description != "Third" {
+		t.Errorf("Expected cursor on 'Third', got index %d", m.cursor)
+	}
+
+	// A task that no longer exists falls back to the top
+	if err := store.Delete(store.tasks[2].ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	m = initialModel(store, reloaded)
+	if m.cursor != 0 {
+		t.Errorf("Expected cursor 0 for a missing task, got %d", m.cursor)
+	}
+}
+
+func TestModel_QuietModeHidesMessages(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	m.message = "Task created successfully"
+	if !contains(m.View(), "Task created successfully") {
+		t.Fatal("Expected the message bar by default")
+	}
+
+	m.config.ShowMessages = false
+	if contains(m.View(), "Task created successfully") {
+		t.Error("Expected no message bar in quiet mode")
+	}
+	if m.message != "Task created successfully" {
+		t.Error("Expected the message to be kept internally")
+	}
+
+	// Prompts outside list mode are still shown
+	m.askConfirm("Delete 3 tasks?", func(m *model) {})
+	if !contains(m.View(), "Delete 3 tasks? (y/n)") {
+		t.Error("Expected confirmation prompt in quiet mode")
+	}
+}
+
+func TestModel_ListViewWrapsLongDescriptions(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	desc := "Write the quarterly report covering revenue, hiring plans and the infrastructure migration timeline"
+	if err := m.store.Add(desc, "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+	m.width = 40
+
+	row := m.renderListRow(m.tasks[0], true)
+	lines := strings.Split(ansi.Strip(row), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("Expected the description to wrap over several lines, got %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], ">") {
+		t.Errorf("Expected the cursor on the first line, got %q", lines[0])
+	}
+	indent := lipgloss.Width(ansi.Strip(m.renderListRow(Task{Status: StatusPending}, true)))
+	for i, line := range lines {
+		if w := lipgloss.Width(line); w > m.width {
+			t.Errorf("Line %d is %d wide, over the width of %d: %q", i, w, m.width, line)
+		}
+		if i > 0 && !strings.HasPrefix(line, strings.Repeat(" ", indent)) {
+			t.Errorf("Expected line %d to be indented by %d, got %q", i, indent, line)
+		}
+	}
+	if got := strings.Join(strings.Fields(strings.Join(lines, " ")), " "); !strings.Contains(got, desc) {
+		t.Errorf("Expected the full description across the lines, got %q", got)
+	}
+
+	// Unknown width leaves the row on one line
+	m.width = 0
+	if strings.Contains(m.renderListRow(m.tasks[0], true), "\n") {
+		t.Error("Expected no wrapping before the width is known")
+	}
+}
+
+func TestModel_TagSelectedTasks(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"One", "Two", "Three"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+	m.marked = map[string]bool{m.tasks[0].ID: true, m.tasks[2].ID: true}
+
+	press := func(m model, key string) model {
+		updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return updatedModel.(model)
+	}
+	enter := func(m model) model {
+		updatedModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		return updatedModel.(model)
+	}
+
+	m = press(m, "a")
+	if m.viewMode != ModeTag {
+		t.Fatalf("Expected tag mode, got %d", m.viewMode)
+	}
+	m.textInput.SetValue("home")
+	m = enter(m)
+	if m.message != `Tagged 2 task(s) with "home"` {
+		t.Errorf("Unexpected message %q", m.message)
+	}
+	for _, task := range m.store.GetAll() {
+		if slices.Contains(task.Tags, "home") != (task.Description != "Two") {
+			t.Errorf("Task %q has tags %v", task.Description, task.Tags)
+		}
+	}
+
+	m = press(m, "A")
+	m.textInput.SetValue("home")
+	m = enter(m)
+	if m.message != `Removed "home" from 2 task(s)` {
+		t.Errorf("Unexpected message %q", m.message)
+	}
+	for _, task := range m.store.GetAll() {
+		if len(task.Tags) != 0 {
+			t.Errorf("Expected %q to have no tags, got %v", task.Description, task.Tags)
+		}
+	}
+}
+
+func TestModel_ClearCategory(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	m = updatedModel.(model)
+	if m.message != "Category cleared" {
+		t.Errorf("Expected 'Category cleared', got '%s'", m.message)
+	}
+	task := m.store.GetAll()[0]
+	if task.Category != "" {
+		t.Errorf("Expected no category, got '%s'", task.Category)
+	}
+
+	// Clearing again does nothing
+	updated := task.UpdatedAt
+	updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	m = updatedModel.(model)
+	if m.message != "Task has no category" {
+		t.Errorf("Expected 'Task has no category', got '%s'", m.message)
+	}
+	if !m.store.GetAll()[0].UpdatedAt.Equal(updated) {
+		t.Error("Expected the task to be left unchanged")
+	}
+}
+
+func TestModel_CategoryPicker(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, category := range []TaskCategory{"home", "work"} {
+		if err := m.store.Add("Task", category); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+
+	key := func(m model, msg tea.KeyMsg) model {
+		updatedModel, _ := m.Update(msg)
+		return updatedModel.(model)
+	}
+
+	m = key(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m.textInput.SetValue("Buy milk")
+	m = key(m, tea.KeyMsg{Type: tea.KeyCtrlP})
+	if m.viewMode != ModeCategoryPicker {
+		t.Fatalf("Expected picker mode, got %d", m.viewMode)
+	}
+	if view := m.View(); !contains(view, "> home") || !contains(view, "work") || !contains(view, pickerNew) {
+		t.Errorf("Expected categories and %q in the picker, got:\n%s", pickerNew, view)
+	}
+
+	m = key(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = key(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.viewMode != ModeCreate || m.categoryInput.Value() != "work" {
+		t.Fatalf("Expected to return to create mode with 'work', got mode %d and %q", m.viewMode, m.categoryInput.Value())
+	}
+	if m.textInput.Value() != "Buy milk" {
+		t.Errorf("Expected the description to be kept, got %q", m.textInput.Value())
+	}
+
+	// "new..." falls back to typing
+	m = key(m, tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = key(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = key(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.viewMode != ModeCreate || m.categoryInput.Value() != "" || m.activeInput != 1 {
+		t.Fatalf("Expected an empty, focused category input, got mode %d, %q, input %d", m.viewMode, m.categoryInput.Value(), m.activeInput)
+	}
+	m = key(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("errands")})
+	m = key(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.store.GetCategories(); !slices.Contains(got, "errands") {
+		t.Errorf("Expected the typed category to be used, got %v", got)
+	}
+}
+
+func TestPriorityColor(t *testing.T) {
+	tests := []struct {
+		priority Priority
+		want     string
+	}{
+		{PriorityNone, ""},
+		{PriorityLow, colorPriorityLow},
+		{PriorityMedium, colorPriorityMedium},
+		{PriorityHigh, colorPriorityHigh},
+	}
+	for _, tt := range tests {
+		if got := priorityColor(tt.priority); got != tt.want {
+			t.Errorf("priorityColor(%s) = %q, want %q", tt.priority, got, tt.want)
+		}
+	}
+
+	// Priority colors stay distinguishable from the status colors
+	for _, tt := range tests[1:] {
+		for _, status := range []TaskStatus{StatusPending, StatusInProgress, StatusDone} {
+			if priorityColor(tt.priority) == (model{}).getStatusColor(status) {
+				t.Errorf("Priority %s shares its color with status %s", tt.priority, status)
+			}
+		}
+	}
+}
+
+func TestModel_SetPriority(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Ship release", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updatedModel, _ := m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}})
+	m = updatedModel.(model)
+	if got := m.store.GetAll()[0].Priority; got != PriorityHigh {
+		t.Fatalf("Expected high priority, got %s", got)
+	}
+	if m.message != "Priority: high" {
+		t.Errorf("Expected 'Priority: high', got '%s'", m.message)
+	}
+	if row := m.renderTableRow(m.tasks[0], false); !strings.HasPrefix(ansi.Strip(row), "▎") {
+		t.Errorf("Expected a priority border, got %q", ansi.Strip(row))
+	}
+	if detail := ansi.Strip(m.renderDetail(m.tasks[0])); !contains(detail, "Priority:    high") {
+		t.Errorf("Expected the priority in the details, got:\n%s", detail)
+	}
+
+	updatedModel, _ = m.updateListMode(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'0'}})
+	m = updatedModel.(model)
+	if row := m.renderTableRow(m.tasks[0], false); strings.HasPrefix(ansi.Strip(row), "▎") {
+		t.Error("Expected no border without a priority")
+	}
+	if contains(m.renderDetail(m.tasks[0]), "Priority") {
+		t.Error("Expected no priority in the details when unset")
+	}
+}
+
+func TestModel_CustomTitleAndEmptyMessage(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	m.config.Title = "Mis tareas"
+	m.config.EmptyMessage = "Nada por hacer. Pulsa 'n' para crear una."
+	view := m.View()
+	if !contains(view, "Mis tareas") || !contains(view, "Nada por hacer") {
+		t.Errorf("Expected the configured title and empty message, got:\n%s", view)
+	}
+	if contains(view, "No tasks yet") {
+		t.Error("Expected the default empty message to be replaced")
+	}
+
+	// Empty values keep the defaults
+	m.config.Title = ""
+	m.config.EmptyMessage = ""
+	if view := m.View(); !contains(view, defaultTitle) || !contains(view, defaultEmptyMessage) {
+		t.Errorf("Expected the defaults for empty values, got:\n%s", view)
+	}
+}
+
+func TestModel_WorkingOnHeader(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Write report", "Review PR"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := m.store.GetAll()
+	m.refreshTasks()
+
+	// None in progress: normal header
+	if view := m.View(); contains(view, "Working on") || !contains(view, defaultTitle) {
+		t.Errorf("Expected the normal header with no task in progress, got:\n%s", view)
+	}
+
+	// Exactly one: shown even when filtered out of the view
+	if err := m.store.UpdateStatus(tasks[0].ID, StatusInProgress); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	pending := StatusPending
+	m.filterStatus = &pending
+	m.refreshTasks()
+	if view := m.View(); !contains(view, "▶ Working on: Write report") {
+		t.Errorf("Expected the working-on line, got:\n%s", view)
+	}
+
+	// Several: back to the normal header
+	if err := m.store.UpdateStatus(tasks[1].ID, StatusInProgress); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if view := m.View(); contains(view, "Working on") || !contains(view, defaultTitle) {
+		t.Errorf("Expected the normal header with two tasks in progress, got:\n%s", view)
+	}
+}
+
+func TestModel_TerminalTooSmall(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 15, Height: 4})
+	m = updated.(model)
+	if view := m.View(); !contains(view, "Terminal too small — resize to at least 20x6") {
+		t.Errorf("Expected the resize warning, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 3})
+	m = updated.(model)
+	if view := m.View(); !contains(view, "Terminal too small") {
+		t.Errorf("Expected the resize warning for a short terminal, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = updated.(model)
+	if view := m.View(); contains(view, "Terminal too small") || !contains(view, defaultTitle) {
+		t.Errorf("Expected the normal view after resizing, got:\n%s", view)
+	}
+}
+
+func TestModel_EditPreview(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = updated.(model)
+	if m.viewMode != ModeEdit {
+		t.Fatalf("Expected edit mode, got %v", m.viewMode)
+	}
+
+	m.textInput.SetValue("Write final report")
+	m.categoryInput.SetValue("office")
+	for _, asTable := range []bool{true, false} {
+		m.viewAsTable = asTable
+		view := m.View()
+		if !contains(view, "Preview:") || !contains(view, "Write final report") || !contains(view, "office") {
+			t.Errorf("Expected the preview to reflect the inputs (table=%v), got:\n%s", asTable, view)
+		}
+	}
+
+	// The preview doesn't touch the stored task
+	if task := m.store.GetAll()[0]; task.Description != "Write report" || task.Category != "work" {
+		t.Errorf("Expected the stored task to be unchanged, got %+v", task)
+	}
+
+	m.viewMode = ModeCreate
+	if contains(m.View(), "Preview:") {
+		t.Error("Expected no preview when creating a task")
+	}
+}
+
+func TestModel_RenderTaskRow(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	withCategory := Task{Seq: 1, Description: "Write report", Status: StatusPending, Category: "work"}
+	withoutCategory := Task{Seq: 2, Description: "Call mom", Status: StatusPending}
+
+	for _, opts := range []rowOpts{{table: true}, {table: false}} {
+		selected := ansi.Strip(m.renderTaskRow(withCategory, true, opts))
+		unselected := ansi.Strip(m.renderTaskRow(withCategory, false, opts))
+		if !strings.Contains(selected, ">") || strings.Contains(unselected, ">") {
+			t.Errorf("Expected only the selected row to have a cursor (table=%v):\n%q\n%q", opts.table, selected, unselected)
+		}
+		if !strings.Contains(unselected, "Write report") || !strings.Contains(unselected, "work") {
+			t.Errorf("Expected description and category in the row (table=%v), got %q", opts.table, unselected)
+		}
+
+		row := ansi.Strip(m.renderTaskRow(withoutCategory, false, opts))
+		if !strings.Contains(row, "Call mom") || strings.Contains(row, "[") {
+			t.Errorf("Expected no category in the row (table=%v), got %q", opts.table, row)
+		}
+	}
+
+	if row := ansi.Strip(m.renderTaskRow(withCategory, false, rowOpts{table: true})); !strings.Contains(row, "#1") {
+		t.Errorf("Expected the table row to include the task number, got %q", row)
+	}
+	if row := ansi.Strip(m.renderTaskRow(withCategory, false, rowOpts{})); !strings.Contains(row, "[work]") {
+		t.Errorf("Expected the list row to show the category in brackets, got %q", row)
+	}
+}
+
+func TestExpandSnippet(t *testing.T) {
+	snippets := map[string]string{"fu": "Follow up:", "pr": "Review PR:"}
+
+	tests := []struct {
+		name    string
+		value   string
+		pos     int
+		want    string
+		wantPos int
+		ok      bool
+	}{
+		{"at start", "fu ", 3, "Follow up: ", 11, true},
+		{"after a word", "today fu ", 9, "today Follow up: ", 17, true},
+		{"before existing text", "pr  with Dana", 3, "Review PR:  with Dana", 11, true},
+		{"inside a word", "stuffu ", 7, "stuffu ", 7, false},
+		{"unknown trigger", "fix ", 4, "fix ", 4, false},
+		{"no space before the cursor", "fu", 2, "fu", 2, false},
+		{"double space", "fu  ", 4, "fu  ", 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, pos, ok := expandSnippet(tt.value, tt.pos, snippets)
+			if got != tt.want || pos != tt.wantPos || ok != tt.ok {
+				t.Errorf("expandSnippet(%q, %d) = %q, %d, %v; want %q, %d, %v", tt.value, tt.pos, got, pos, ok, tt.want, tt.wantPos, tt.ok)
+			}
+		})
+	}
+}
+
+func TestModel_SnippetExpansionInCreateMode(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	m.config.Snippets = map[string]string{"fu": "Follow up:"}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(model)
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("fu")},
+		{Type: tea.KeySpace, Runes: []rune{' '}},
+		{Type: tea.KeyRunes, Runes: []rune("Dana")},
+	} {
+		updated, _ = m.Update(key)
+		m = updated.(model)
+	}
+	if got := m.textInput.Value(); got != "Follow up: Dana" {
+		t.Errorf("Expected the snippet to expand, got %q", got)
+	}
+
+	// Triggers typed in the category field are left alone
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("fu")},
+		{Type: tea.KeySpace, Runes: []rune{' '}},
+	} {
+		updated, _ = m.Update(key)
+		m = updated.(model)
+	}
+	if got := m.categoryInput.Value(); got != "fu " {
+		t.Errorf("Expected the category to stay as typed, got %q", got)
+	}
+}
+
+func TestModel_CreatePrefillsFilteredCategory(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(model)
+	if got := m.categoryInput.Value(); got != "" {
+		t.Errorf("Expected an empty category without a filter, got %q", got)
+	}
+
+	m.viewMode = ModeList
+	work := TaskCategory("work")
+	m.filterCategory = &work
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(model)
+	if got := m.categoryInput.Value(); got != "work" {
+		t.Fatalf("Expected the category to be prefilled from the filter, got %q", got)
+	}
+
+	// Still editable
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = updated.(model)
+	if got := m.categoryInput.Value(); got != "wor" {
+		t.Errorf("Expected the prefilled category to be editable, got %q", got)
+	}
+}
+
+func TestModel_DetailLinks(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+	m.viewMode = ModeDetail
+
+	press := func(keys ...tea.KeyMsg) {
+		t.Helper()
+		for _, key := range keys {
+			updated, _ := m.Update(key)
+			m = updated.(model)
+		}
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	press(runes("L"))
+	if m.viewMode != ModeDetail || m.message != "No links to remove" {
+		t.Fatalf("Expected removing without links to be refused, got mode %v: %q", m.viewMode, m.message)
+	}
+
+	press(runes("l"), runes("https://example.com"), enter)
+	press(runes("l"), runes("~/notes.md"), enter)
+	if m.viewMode != ModeDetail {
+		t.Fatalf("Expected to return to the detail view, got %v", m.viewMode)
+	}
+	view := m.View()
+	if !contains(view, "1. https://example.com") || !contains(view, "2. ~/notes.md") {
+		t.Errorf("Expected both links listed in the detail view, got:\n%s", view)
+	}
+
+	press(runes("l"), enter)
+	if m.message != "Link cannot be empty" {
+		t.Errorf("Expected an empty link to be rejected, got %q", m.message)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+
+	// Links can be removed by number
+	press(runes("L"), runes("1"), enter)
+	if got := m.store.GetAll()[0].Links; !slices.Equal(got, []string{"~/notes.md"}) {
+		t.Errorf("Expected the first link removed, got %q", got)
+	}
+}
+
+func TestModel_BackgroundSave(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	m.store.DeferSaves()
+	m.config.SaveDebounceMs = 0 // see TestModel_SaveDebounce
+
+	// findSave runs cmd and returns the save result among its messages
+	findSave := func(cmd tea.Cmd) (tasksSavedMsg, bool) {
+		t.Helper()
+		if cmd == nil {
+			return tasksSavedMsg{}, false
+		}
+		msgs := []tea.Msg{cmd()}
+		if batch, ok := msgs[0].(tea.BatchMsg); ok {
+			msgs = nil
+			for _, c := range batch {
+				msgs = append(msgs, c())
+			}
+		}
+		for _, msg := range msgs {
+			if saved, ok := msg.(tasksSavedMsg); ok {
+				return saved, true
+			}
+		}
+		return tasksSavedMsg{}, false
+	}
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	// Marking the task done starts a save instead of writing inline
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updated.(model)
+	if !m.saving || !contains(m.View(), "Saving…") {
+		t.Fatalf("Expected a save in progress, got saving=%v:\n%s", m.saving, m.View())
+	}
+
+	saved, ok := findSave(cmd)
+	if !ok || saved.err != nil {
+		t.Fatalf("Expected a successful save, got %+v (%v)", saved, ok)
+	}
+
+	// A change made before the result arrives doesn't start a second save
+	updated, second := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = updated.(model)
+	if _, ok := findSave(second); ok {
+		t.Error("Expected no concurrent save")
+	}
+
+	updated, cmd = m.Update(saved)
+	m = updated.(model)
+
+	// The change made during the first save is picked up by the next one
+	if !m.saving {
+		t.Fatal("Expected a follow-up save for the change made during the first")
+	}
+	saved, ok = findSave(cmd)
+	if !ok {
+		t.Fatal("Expected the follow-up save command")
+	}
+	updated, cmd = m.Update(saved)
+	m = updated.(model)
+	if m.saving || m.store.Unsaved() || cmd != nil || !contains(m.View(), "Saved") {
+		t.Errorf("Expected everything saved, got saving=%v unsaved=%v", m.saving, m.store.Unsaved())
+	}
+
+	// A failed save is reported and not retried in a loop
+	updated, cmd = m.Update(tasksSavedMsg{err: errors.New("disk full")})
+	m = updated.(model)
+	if !contains(m.message, "Error saving tasks: disk full") || cmd != nil {
+		t.Errorf("Expected the save error to be shown, got %q", m.message)
+	}
+}
+
+func TestModel_ToggleCategories(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "office"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	m = updated.(model)
+	if m.config.ShowCategories {
+		t.Fatal("Expected c to hide categories")
+	}
+	for _, asTable := range []bool{true, false} {
+		m.viewAsTable = asTable
+		row := ansi.Strip(m.renderTaskRow(m.tasks[0], false, rowOpts{table: asTable}))
+		if strings.Contains(row, "office") || !strings.Contains(row, "Write report") {
+			t.Errorf("Expected the row without its category (table=%v), got %q", asTable, row)
+		}
+	}
+	m.viewAsTable = true
+	if contains(m.View(), "Category") {
+		t.Error("Expected no Category column header while hidden")
+	}
+
+	// Filtering by category still works while hidden
+	office := TaskCategory("office")
+	m.filterCategory = &office
+	m.refreshTasks()
+	if len(m.tasks) != 1 {
+		t.Errorf("Expected the category filter to keep working, got %d tasks", len(m.tasks))
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	m = updated.(model)
+	if row := ansi.Strip(m.renderTaskRow(m.tasks[0], false, rowOpts{table: true})); !strings.Contains(row, "office") {
+		t.Errorf("Expected the category back, got %q", row)
+	}
+}
+
+func TestModel_StartOnEdit(t *testing.T) {
+	tests := []struct {
+		name        string
+		startOnEdit bool
+		status      TaskStatus
+		wantStatus  TaskStatus
+		wantMessage string
+	}{
+		{"off", false, StatusPending, StatusPending, "Task updated successfully"},
+		{"pending", true, StatusPending, StatusInProgress, "Task updated and marked in progress"},
+		{"already in progress", true, StatusInProgress, StatusInProgress, "Task updated successfully"},
+		{"done", true, StatusDone, StatusDone, "Task updated successfully"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, tmpDir := createTestModel(t)
+			defer func() { _ = os.RemoveAll(tmpDir) }()
+			m.config.StartOnEdit = tt.startOnEdit
+
+			if err := m.store.Add("Write report", "work"); err != nil {
+				t.Fatalf("Failed to add task: %v", err)
+			}
+			id := m.store.GetAll()[0].ID
+			if err := m.store.UpdateStatus(id, tt.status); err != nil {
+				t.Fatalf("Failed to update status: %v", err)
+			}
+			m.refreshTasks()
+
+			updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+			m = updated.(model)
+			m.textInput.SetValue("Write final report")
+			updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			m = updated.(model)
+
+			task, _ := m.store.Get(id)
+			if task.Description != "Write final report" || task.Status != tt.wantStatus {
+				t.Errorf("Expected %q with status %s, got %q with %s", "Write final report", tt.wantStatus, task.Description, task.Status)
+			}
+			if m.message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, m.message)
+			}
+		})
+	}
+}
+
+func TestModel_DetailHistory(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	id := m.store.GetAll()[0].ID
+	for _, status := range []TaskStatus{StatusInProgress, StatusDone} {
+		if err := m.store.UpdateStatus(id, status); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+	}
+	m.refreshTasks()
+
+	detail := m.renderDetail(m.tasks[0])
+	first := strings.Index(detail, "pending → in-progress")
+	second := strings.Index(detail, "in-progress → done")
+	if !contains(detail, "History:") || first == -1 || second < first {
+		t.Errorf("Expected the transitions as a timeline, got:\n%s", detail)
+	}
+}
+
+func TestNextByFirstLetter(t *testing.T) {
+	tasks := []Task{
+		{Description: "Buy milk"},
+		{Description: "write report"},
+		{Description: "Book flights"},
+		{Description: "Call mom"},
+	}
+
+	tests := []struct {
+		name string
+		from int
+		r    rune
+		want int
+	}{
+		{"next match", 0, 'b', 2},
+		{"wraps around", 2, 'b', 0},
+		{"ignores case", 0, 'W', 1},
+		{"only match is the current task", 1, 'w', 1},
+		{"no match", 0, 'z', -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextByFirstLetter(tasks, tt.from, tt.r); got != tt.want {
+				t.Errorf("nextByFirstLetter(from %d, %q) = %d, want %d", tt.from, tt.r, got, tt.want)
+			}
+		})
+	}
+
+	if got := nextByFirstLetter(nil, 0, 'a'); got != -1 {
+		t.Errorf("Expected -1 for no tasks, got %d", got)
+	}
+}
+
+func TestModel_TypeAheadMode(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Buy milk", "Deploy app", "Book flights"} {
+		if err := m.store.Add(desc, "home"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+
+	press := func(s string) {
+		t.Helper()
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+		m = updated.(model)
+	}
+
+	press("'")
+	if m.viewMode != ModeTypeAhead {
+		t.Fatalf("Expected type-ahead mode, got %v", m.viewMode)
+	}
+
+	// Letters jump instead of running commands: d would mark a task done
+	press("d")
+	if m.tasks[m.cursor].Description != "Deploy app" || m.tasks[m.cursor].Status != StatusPending {
+		t.Errorf("Expected to jump to 'Deploy app' without changing it, got %+v", m.tasks[m.cursor])
+	}
+	press("b")
+	first := m.tasks[m.cursor].Description
+	press("b")
+	second := m.tasks[m.cursor].Description
+	if first == second || !strings.HasPrefix(first, "B") || !strings.HasPrefix(second, "B") {
+		t.Errorf("Expected repeated presses to cycle the B tasks, got %q then %q", first, second)
+	}
+
+	press("z")
+	if m.tasks[m.cursor].Description != second || !contains(m.message, "No task starts with") {
+		t.Errorf("Expected the cursor to stay put with a note, got %q", m.message)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	if m.viewMode != ModeList {
+		t.Errorf("Expected ESC to leave type-ahead mode, got %v", m.viewMode)
+	}
+}
+
+func TestModel_GroupOrderFromConfig(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Pending A", "Done B", "Working C"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	tasks := m.store.GetAll()
+	if err := m.store.UpdateStatus(tasks[1].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if err := m.store.UpdateStatus(tasks[2].ID, StatusInProgress); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.GroupOrder = []TaskStatus{StatusPending, StatusInProgress, StatusDone}
+	m = initialModel(m.store, cfg)
+	m.groupByStatus = true
+	m.refreshTasks()
+
+	view := m.View()
+	pending := strings.Index(view, "Pending\n")
+	inProgress := strings.Index(view, "In Progress")
+	done := strings.Index(view, "Done\n")
+	if pending == -1 || inProgress == -1 || done == -1 || !(pending < inProgress && inProgress < done) {
+		t.Errorf("Expected groups in order Pending, In Progress, Done:\n%s", view)
+	}
+	if m.tasks[0].Description != "Pending A" {
+		t.Errorf("Expected the cursor order to follow the groups, got %q first", m.tasks[0].Description)
+	}
+}
+
+func TestValidateGroupOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []TaskStatus
+		wantErr bool
+	}{
+		{"default", statusGroupOrder, false},
+		{"reordered", []TaskStatus{StatusDone, StatusPending, StatusInProgress}, false},
+		{"missing a status", []TaskStatus{StatusPending, StatusDone}, true},
+		{"duplicate", []TaskStatus{StatusPending, StatusPending, StatusDone}, true},
+		{"unknown status", []TaskStatus{StatusPending, StatusInProgress, "later"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateGroupOrder(tt.order); (err != nil) != tt.wantErr {
+				t.Errorf("validateGroupOrder(%v) = %v, wantErr %v", tt.order, err, tt.wantErr)
+			}
+		})
+	}
+
+	// An invalid order falls back to the default with a warning
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	cfg := DefaultConfig()
+	cfg.GroupOrder = []TaskStatus{StatusDone}
+	m = initialModel(m.store, cfg)
+	if !slices.Equal(m.groupOrder, statusGroupOrder) || !contains(m.message, "Invalid group_order") {
+		t.Errorf("Expected the default order with a warning, got %v and %q", m.groupOrder, m.message)
+	}
+}
+
+func TestPickRandomPending(t *testing.T) {
+	tasks := []Task{
+		{Description: "Done A", Status: StatusDone},
+		{Description: "Pending B", Status: StatusPending},
+		{Description: "Working C", Status: StatusInProgress},
+		{Description: "Pending D", Status: StatusPending},
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	seen := make(map[int]bool)
+	for range 100 {
+		i := pickRandomPending(tasks, rng)
+		if i < 0 || i >= len(tasks) || tasks[i].Status != StatusPending {
+			t.Fatalf("Expected a pending task, got index %d", i)
+		}
+		seen[i] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("Expected both pending tasks to be picked at some point, got %v", seen)
+	}
+
+	if i := pickRandomPending(tasks[:1], rng); i != -1 {
+		t.Errorf("Expected -1 without pending tasks, got %d", i)
+	}
+}
+
+func TestModel_PickRandomTask(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	m.rng = rand.New(rand.NewSource(1))
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	m = updated.(model)
+	if m.message != "No pending tasks to pick from" {
+		t.Errorf("Expected a note without pending tasks, got %q", m.message)
+	}
+
+	for _, desc := range []string{"Write report", "Buy milk"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := m.store.UpdateStatus(m.store.GetAll()[0].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	m.refreshTasks()
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	m = updated.(model)
+	if task := m.getCurrentTask(); task.Description != "Buy milk" || m.message != "Picked: Buy milk" {
+		t.Errorf("Expected the pending task picked, got %q with %q", task.Description, m.message)
+	}
+}
+
+func TestModel_BumpDueDateKeys(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'>'}})
+	m = updated.(model)
+	want := startOfDay(time.Now()).AddDate(0, 0, 1)
+	if due := m.getCurrentTask().DueDate; due == nil || !due.Equal(want) {
+		t.Fatalf("Expected due tomorrow, got %v", due)
+	}
+	if !contains(m.message, want.Format("2006-01-02")) {
+		t.Errorf("Expected the new date shown, got %q", m.message)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'W'}})
+	m = updated.(model)
+	if due := m.getCurrentTask().DueDate; !due.Equal(want.AddDate(0, 0, 7)) {
+		t.Errorf("Expected due a week later, got %v", due)
+	}
+}
+
+func TestModel_ConfirmTimeout(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if cmd := m.askConfirm("Delete this task?", func(m *model) {}); cmd != nil {
+		t.Error("Expected no timeout by default")
+	}
+
+	m.config.ConfirmTimeoutSeconds = 30
+	ran := false
+	cmd := m.askConfirm("Delete this task?", func(m *model) { ran = true })
+	if cmd == nil {
+		t.Fatal("Expected a timeout command")
+	}
+
+	// A timeout from an earlier confirmation leaves this one open
+	updated, _ := m.Update(confirmTimeoutMsg{seq: m.confirm.seq - 1})
+	m = updated.(model)
+	if m.viewMode != ModeConfirm {
+		t.Fatal("Expected a stale timeout to be ignored")
+	}
+
+	updated, _ = m.Update(confirmTimeoutMsg{seq: m.confirm.seq})
+	m = updated.(model)
+	if m.viewMode != ModeList || m.confirm != nil {
+		t.Errorf("Expected the timeout to return to list mode, got mode %v", m.viewMode)
+	}
+	if m.message != "Cancelled" {
+		t.Errorf("Expected a cancelled message, got %q", m.message)
+	}
+	if ran {
+		t.Error("Expected the action not to run on timeout")
+	}
+}
+
+func TestModel_HeaderShowsFilteredCount(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Write report", "Buy milk", "Call mom"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := m.store.UpdateStatus(m.store.GetAll()[0].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	m.refreshTasks()
+	if contains(m.View(), "filtered") {
+		t.Error("Expected no filtered count without a filter")
+	}
+
+	pending := StatusPending
+	m.filterStatus = &pending
+	m.refreshTasks()
+	if view := m.View(); !contains(view, "showing 2 of 3 (1 filtered)") {
+		t.Errorf("Expected the filtered count in the header, got:\n%s", view)
+	}
+}
+
+func TestModel_SwitchStore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m, _ := createTestModel(t)
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	m = updated.(model)
+	if !contains(m.message, "No project task list") {
+		t.Errorf("Expected a note without a project list, got %q", m.message)
+	}
+
+	global, err := dataDir()
+	if err != nil {
+		t.Fatalf("dataDir failed: %v", err)
+	}
+	m.store, err = newTaskStoreIn(DefaultConfig(), global)
+	if err != nil {
+		t.Fatalf("Failed to open global store: %v", err)
+	}
+	if err := m.store.Add("Global task", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.localDir = filepath.Join(t.TempDir(), localDirName)
+	if err := os.Mkdir(m.localDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	local, err := newTaskStoreIn(DefaultConfig(), m.localDir)
+	if err != nil {
+		t.Fatalf("Failed to open project store: %v", err)
+	}
+	if err := local.Add("Project task", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+	if !contains(m.View(), "global") {
+		t.Error("Expected the header to name the global list")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	m = updated.(model)
+	if m.store.Dir() != m.localDir {
+		t.Fatalf("Expected the project store, got %s", m.store.Dir())
+	}
+	if len(m.tasks) != 1 || m.tasks[0].Description != "Project task" {
+		t.Errorf("Expected the project tasks, got %v", m.tasks)
+	}
+	if !contains(m.View(), "project") {
+		t.Error("Expected the header to name the project list")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	m = updated.(model)
+	if m.store.Dir() != global || len(m.tasks) != 1 || m.tasks[0].Description != "Global task" {
+		t.Errorf("Expected to be back on the global tasks, got %v in %s", m.tasks, m.store.Dir())
+	}
+}
+
+func TestModel_ToggleNextAction(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Write report", "Buy milk"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.config.PinNextAction = true
+	m.refreshTasks()
+	m.cursor = 1
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	m = updated.(model)
+	if m.message != "Next action: Buy milk" {
+		t.Errorf("Expected the next action message, got %q", m.message)
+	}
+	if m.tasks[0].Description != "Buy milk" || m.cursor != 0 {
+		t.Errorf("Expected the next action pinned to the top with the cursor on it, got %q at %d", m.tasks[0].Description, m.cursor)
+	}
+	if !contains(m.View(), "🎯 Buy milk") {
+		t.Error("Expected the next action marked in the list")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	m = updated.(model)
+	if m.getCurrentTask().NextAction || m.message != "Next action cleared" {
+		t.Errorf("Expected the next action cleared, got %q", m.message)
+	}
+}
+
+func TestModel_BuryTask(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Dreaded chore", "Write report"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'B'}})
+	m = updated.(model)
+	if m.message != "Buried: Dreaded chore" {
+		t.Errorf("Expected the bury message, got %q", m.message)
+	}
+	if m.cursor != 1 || m.getCurrentTask().Description != "Dreaded chore" {
+		t.Errorf("Expected the cursor to follow the task to the bottom, got %d", m.cursor)
+	}
+}
+
+func TestModel_CustomStatusIcons(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := &TaskStore{filepath: filepath.Join(tmpDir, "tasks.json"), tasks: []Task{}}
+	for _, desc := range []string{"Write report", "Buy milk"} {
+		if err := store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := store.UpdateStatus(store.GetAll()[0].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.StatusIcons = map[TaskStatus]string{StatusPending: "[ ]", StatusDone: "[x]"}
+	m := initialModel(store, cfg)
+	view := m.View()
+	if !contains(view, "[x]") || !contains(view, "[ ]") {
+		t.Errorf("Expected the custom icons in the view, got:\n%s", view)
+	}
+	if icon := m.getStatusIcon(StatusInProgress); icon != "⟳" {
+		t.Errorf("Expected the default icon for an unconfigured status, got %q", icon)
+	}
+
+	for _, icons := range []map[TaskStatus]string{
+		{StatusDone: " "},
+		{StatusDone: "[done]"},
+		{"later": "?"},
+	} {
+		cfg.StatusIcons = icons
+		m := initialModel(store, cfg)
+		if !contains(m.message, "Invalid status_icons") || m.getStatusIcon(StatusDone) != "✓" {
+			t.Errorf("Expected %v rejected with the defaults kept, got %q", icons, m.message)
+		}
+		// The config is saved on quit, so the typo must stay in it
+		if len(m.config.StatusIcons) != len(icons) {
+			t.Errorf("Expected status_icons left in the config, got %v", m.config.StatusIcons)
+		}
+	}
+}
+
+func TestModel_NudgePriority(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+	press := func(key rune) {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		m = updated.(model)
+	}
+
+	press('+')
+	if got := m.getCurrentTask().Priority; got != PriorityLow || m.message != "Priority: low" {
+		t.Errorf("Expected low priority after +, got %v with %q", got, m.message)
+	}
+	press('_')
+	if got := m.getCurrentTask().Priority; got != PriorityNone {
+		t.Errorf("Expected no priority after _, got %v", got)
+	}
+}
+
+func TestModel_NudgePriorityClamped(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+	press := func(key rune) {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		m = updated.(model)
+	}
+
+	// Decrement at the minimum
+	changes := m.store.changes
+	press('_')
+	if got := m.getCurrentTask().Priority; got != PriorityNone || m.store.changes != changes {
+		t.Errorf("Expected _ at no priority to change nothing, got %v", got)
+	}
+	if m.message != "Priority is already none" {
+		t.Errorf("Expected a note about the limit, got %q", m.message)
+	}
+
+	// Increment at the maximum
+	if err := m.store.SetPriority(m.getCurrentTask().ID, PriorityHigh); err != nil {
+		t.Fatalf("SetPriority failed: %v", err)
+	}
+	m.refreshTasks()
+	changes = m.store.changes
+	press('+')
+	if got := m.getCurrentTask().Priority; got != PriorityHigh || m.store.changes != changes {
+		t.Errorf("Expected + at high priority to change nothing, got %v", got)
+	}
+}
+
+func TestModel_FinishAndAdvance(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"First", "Already done", "Second", "Third"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := m.store.UpdateStatus(m.store.GetAll()[1].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	m.refreshTasks()
+	m.cursor = m.indexOfTask(m.store.GetAll()[0].ID)
+
+	finish := func() {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+		m = updated.(model)
+	}
+
+	finish()
+	if got := m.getCurrentTask().Description; got != "Second" {
+		t.Errorf("Expected the cursor on the next incomplete task, got %q", got)
+	}
+	if task, _ := m.store.Get(m.store.GetAll()[0].ID); task.Status != StatusDone {
+		t.Errorf("Expected the finished task to be done, got %s", task.Status)
+	}
+
+	m.cursor = m.indexOfTask(m.store.GetAll()[3].ID)
+	finish()
+	if got := m.getCurrentTask().Description; got != "Second" {
+		t.Errorf("Expected the cursor to wrap around to the top, got %q", got)
+	}
+
+	finish()
+	if m.message != "All done!" {
+		t.Errorf("Expected the all done message, got %q", m.message)
+	}
+}
+
+func TestModel_Reminders(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Call the bank", "home"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'M'}})
+	m = updated.(model)
+	if m.viewMode != ModeReminder {
+		t.Fatalf("Expected reminder mode, got %v", m.viewMode)
+	}
+	past := time.Now().Add(-time.Hour).Format("2006-01-02 15:04")
+	m.textInput.SetValue(past)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	if m.viewMode != ModeList {
+		t.Errorf("Expected to return to list mode, got %v", m.viewMode)
+	}
+	if !contains(m.View(), "Reminders") || !contains(m.View(), "Call the bank") {
+		t.Errorf("Expected the reminder banner, got:\n%s", m.View())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	m = updated.(model)
+	if contains(m.View(), "Reminders") {
+		t.Error("Expected w to dismiss the reminder banner")
+	}
+}
+
+func TestParseReminder(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.Local)
+	at := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		input string
+		want  *time.Time
+	}{
+		{"", nil},
+		{"2h", at(now.Add(2 * time.Hour))},
+		{"2026-05-11 09:30", at(time.Date(2026, 5, 11, 9, 30, 0, 0, time.Local))},
+		{"2026-05-11", at(time.Date(2026, 5, 11, 0, 0, 0, 0, time.Local))},
+	}
+	for _, tt := range tests {
+		got, err := parseReminder(tt.input, now)
+		if err != nil {
+			t.Errorf("parseReminder(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+			t.Errorf("parseReminder(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"tomorrow", "-2h", "2026-13-01"} {
+		if _, err := parseReminder(input, now); err == nil {
+			t.Errorf("Expected parseReminder(%q) to fail", input)
+		}
+	}
+}
+
+func TestModel_SaveDebounce(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.store.DeferSaves()
+	m.refreshTasks()
+
+	// A burst of changes, like holding a key, only schedules the save
+	for _, key := range []rune{'+', '+', '+', 'i', 'd'} {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		m = updated.(model)
+		if m.saving {
+			t.Fatalf("Expected no save to start within the window after %q", key)
+		}
+	}
+	if !m.saveScheduled || !m.store.Unsaved() {
+		t.Fatalf("Expected a scheduled save, got scheduled=%v unsaved=%v", m.saveScheduled, m.store.Unsaved())
+	}
+
+	// The end of the window starts a single save with every change
+	updated, cmd := m.Update(saveTickMsg{})
+	m = updated.(model)
+	if !m.saving || m.saveScheduled || cmd == nil {
+		t.Fatalf("Expected the save to start, got saving=%v scheduled=%v", m.saving, m.saveScheduled)
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c != nil {
+				if saved, ok := c().(tasksSavedMsg); ok {
+					msg = saved
+				}
+			}
+		}
+	}
+	saved, ok := msg.(tasksSavedMsg)
+	if !ok || saved.err != nil {
+		t.Fatalf("Expected a successful save, got %#v", msg)
+	}
+	updated, cmd = m.Update(saved)
+	m = updated.(model)
+	if m.saving || m.saveScheduled || m.store.Unsaved() || cmd != nil {
+		t.Errorf("Expected nothing left to save, got saving=%v scheduled=%v unsaved=%v", m.saving, m.saveScheduled, m.store.Unsaved())
+	}
+
+	reloaded := &TaskStore{filepath: m.store.filepath, tasks: []Task{}}
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	task := reloaded.GetAll()[0]
+	if task.Status != StatusDone || task.Priority != PriorityHigh {
+		t.Errorf("Expected the last state of the burst on disk, got %s/%s", task.Status, task.Priority)
+	}
+}
+
+func TestModel_ToggleShowIDs(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+	id := m.store.GetAll()[0].ID
+
+	if contains(m.View(), id) {
+		t.Fatalf("Expected IDs hidden by default, got:\n%s", m.View())
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'I'}})
+	m = updated.(model)
+	if !contains(m.View(), id) {
+		t.Errorf("Expected the task ID in the list view, got:\n%s", m.View())
+	}
+	m.viewAsTable = true
+	if !contains(m.View(), id) {
+		t.Errorf("Expected the task ID in the table view, got:\n%s", m.View())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'I'}})
+	m = updated.(model)
+	if contains(m.View(), id) {
+		t.Error("Expected I to hide the IDs again")
+	}
+}
+
+func TestModel_HideFutureTasks(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, desc := range []string{"Write report", "Plan vacation"} {
+		if err := m.store.Add(desc, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	m.refreshTasks()
+	m.cursor = 1
+
+	// Schedule the second task for next week through the S prompt
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	m = updated.(model)
+	if m.viewMode != ModeStartDate {
+		t.Fatalf("Expected start date mode, got %v", m.viewMode)
+	}
+	m.textInput.SetValue("1w")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+
+	if len(m.tasks) != 1 || m.tasks[0].Description != "Write report" {
+		t.Fatalf("Expected the future task hidden, got %+v", m.tasks)
+	}
+	if !contains(m.View(), "1 not started") {
+		t.Errorf("Expected the header to count the hidden task, got:\n%s", m.View())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'H'}})
+	m = updated.(model)
+	if len(m.tasks) != 2 || !contains(m.View(), "🗓 Plan vacation") {
+		t.Errorf("Expected H to reveal the future task, got:\n%s", m.View())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'H'}})
+	m = updated.(model)
+	if len(m.tasks) != 1 {
+		t.Errorf("Expected H to hide it again, got %d tasks", len(m.tasks))
+	}
+}
+
+func TestParseStartDate(t *testing.T) {
+	now := time.Date(2026, 5, 10, 15, 0, 0, 0, time.Local)
+
+	start, err := parseStartDate("3d", now)
+	if err != nil || !start.Equal(time.Date(2026, 5, 13, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Expected 3d to start at midnight three days on, got %v (%v)", start, err)
+	}
+	start, err = parseStartDate("2026-06-01", now)
+	if err != nil || !start.Equal(time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Expected the given date, got %v (%v)", start, err)
+	}
+	if start, err := parseStartDate(" ", now); err != nil || start != nil {
+		t.Errorf("Expected empty input to clear the start date, got %v (%v)", start, err)
+	}
+	if _, err := parseStartDate("someday", now); err == nil {
+		t.Error("Expected an invalid start date to fail")
+	}
+}
+
+func TestModel_ToggleWrapRows(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	desc := "Write the quarterly report covering revenue, hiring plans and the infrastructure migration timeline"
+	if err := m.store.Add(desc, "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.refreshTasks()
+	m.width = 40
+
+	if lines := strings.Split(ansi.Strip(m.renderListRow(m.tasks[0], true)), "\n"); len(lines) < 2 {
+		t.Fatalf("Expected the row wrapped by default, got %q", lines)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	m = updated.(model)
+	if m.config.WrapRows || m.message != "Long rows truncated" {
+		t.Fatalf("Expected z to switch to truncation, got WrapRows=%v %q", m.config.WrapRows, m.message)
+	}
+	row := ansi.Strip(m.renderListRow(m.tasks[0], true))
+	if strings.Contains(row, "\n") || !strings.HasSuffix(row, "…") {
+		t.Errorf("Expected a single truncated line, got %q", row)
+	}
+	if w := lipgloss.Width(row); w > m.width {
+		t.Errorf("Expected the row to fit in %d columns, got %d", m.width, w)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	m = updated.(model)
+	if !strings.Contains(m.renderListRow(m.tasks[0], true), "\n") {
+		t.Error("Expected z to switch back to wrapping")
+	}
+}
+
+func TestModel_SwapDescriptionAndCategory(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(model)
+	m.textInput.SetValue("work")
+	m.categoryInput.SetValue("Write report")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	if m.textInput.Value() != "Write report" || m.categoryInput.Value() != "work" {
+		t.Errorf("Expected the fields swapped, got %q and %q", m.textInput.Value(), m.categoryInput.Value())
+	}
+	if m.viewMode != ModeCreate || m.activeInput != 1 || !m.categoryInput.Focused() || m.textInput.Focused() {
+		t.Errorf("Expected focus to stay on the category input, got active input %d", m.activeInput)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	tasks := m.store.GetAll()
+	if len(tasks) != 1 || tasks[0].Description != "Write report" || tasks[0].Category != "work" {
+		t.Errorf("Expected the swapped values saved, got %+v", tasks)
+	}
+}
+
+func TestModel_CommandLine(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	run := func(line string) {
+		t.Helper()
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{':'}})
+		m = updated.(model)
+		if m.viewMode != ModeCommand {
+			t.Fatalf("Expected command mode, got %v", m.viewMode)
+		}
+		m.textInput.SetValue(line)
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updated.(model)
+	}
+
+	run("new buy milk @home")
+	run("new Write report @work")
+	if tasks := m.store.GetAll(); len(tasks) != 2 || tasks[0].Description != "buy milk" || tasks[0].Category != "home" {
+		t.Fatalf("Expected the tasks created, got %+v", tasks)
+	}
+	if m.viewMode != ModeList {
+		t.Errorf("Expected to return to list mode, got %v", m.viewMode)
+	}
+
+	run("sort description reverse")
+	if m.tasks[0].Description != "Write report" {
+		t.Errorf("Expected the list sorted by description, got %q first", m.tasks[0].Description)
+	}
+
+	if err := m.store.UpdateStatus(m.store.GetAll()[0].ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	run("filter done")
+	if len(m.tasks) != 1 || m.tasks[0].Description != "buy milk" {
+		t.Errorf("Expected only the done task, got %+v", m.tasks)
+	}
+
+	// A mistake stays at the prompt with the error in the message bar
+	run("filter someday")
+	if m.viewMode != ModeCommand || !contains(m.message, "unknown filter") {
+		t.Errorf("Expected to stay in command mode with the error, got %v %q", m.viewMode, m.message)
+	}
+	if m.textInput.Value() != "filter someday" {
+		t.Errorf("Expected the command kept for fixing, got %q", m.textInput.Value())
+	}
+
+	// A task without a category is refused like in the create form
+	m.textInput.SetValue("new call mom")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.viewMode != ModeCommand || len(m.store.GetAll()) != 2 {
+		t.Errorf("Expected the task refused without a category, got %q", m.message)
+	}
+}
+
+func TestModel_ReloadFlushesPendingChanges(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := m.store.Add("Write report", "work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	m.store.DeferSaves()
+	m.refreshTasks()
+
+	// The change waits for the debounce window when ctrl+r comes in
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updated.(model)
+	if !m.saveScheduled {
+		t.Fatal("Expected the save to be waiting for the debounce window")
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updated.(model)
+	if m.tasks[0].Status != StatusDone || m.store.Unsaved() {
+		t.Fatalf("Expected the change written before reloading, got %s (unsaved=%v)", m.tasks[0].Status, m.store.Unsaved())
+	}
+
+	// If the flush fails, nothing is reloaded and the change stays pending
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updated.(model)
+	blocker := filepath.Join(tmpDir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	m.store.filepath = filepath.Join(blocker, "tasks.json")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updated.(model)
+	if !contains(m.message, "Error saving tasks") {
+		t.Errorf("Expected the save error, got %q", m.message)
+	}
+	if !m.store.Unsaved() || m.tasks[0].Status != StatusPending {
+		t.Errorf("Expected the pending change kept, got %s (unsaved=%v)", m.tasks[0].Status, m.store.Unsaved())
+	}
+}
+
+func TestModel_ReloadReportsRepairedDuplicateIDs(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	data := `[{"id":"a","description":"First","seq":1},{"id":"a","description":"Copy","seq":1}]`
+	if err := os.WriteFile(m.store.filepath, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write tasks file: %v", err)
+	}
+
+	// Load writes nothing to stderr, which would land on top of the TUI
+	stderr := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	os.Stderr = w
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	os.Stderr = stderr
+	_ = w.Close()
+	printed, _ := io.ReadAll(r)
+	m = updated.(model)
+
+	if len(printed) > 0 {
+		t.Errorf("Expected nothing on stderr, got %q", printed)
+	}
+	if m.message != "Gave new IDs to 1 tasks that shared an ID with another task" {
+		t.Errorf("Expected the repair in the status line, got %q", m.message)
+	}
+	if len(m.tasks) != 2 || m.tasks[0].ID == m.tasks[1].ID {
+		t.Errorf("Expected two tasks with their own IDs, got %+v", m.tasks)
+	}
+}
+
+func TestModel_TableViewTruncatesByDisplayWidth(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	long := strings.Repeat("Prepare the quarterly report ", 10)
+	for range 2 {
+		if err := m.store.Add(long, "work"); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if err := m.store.TogglePin(m.store.GetAll()[0].ID); err != nil {
+		t.Fatalf("TogglePin failed: %v", err)
+	}
+	m.refreshTasks()
+	m.viewAsTable = true
+
+	pinned := ansi.Strip(m.renderTableRow(m.tasks[0], false))
+	plain := ansi.Strip(m.renderTableRow(m.tasks[1], false))
+	if !utf8.ValidString(pinned) {
+		t.Fatalf("Expected the pinned row to stay valid UTF-8, got %q", pinned)
+	}
+	if !contains(pinned, "📌") || !contains(pinned, "…") {
+		t.Errorf("Expected the pinned description cut with an ellipsis, got %q", pinned)
+	}
+
+	// The category column lines up whatever the description holds
+	if a, b := strings.Index(pinned, "work"), strings.Index(plain, "work"); a < 0 ||
+		lipgloss.Width(pinned[:a]) != lipgloss.Width(plain[:b]) {
+		t.Errorf("Expected the category in the same column:\n%s\n%s", pinned, plain)
+	}
+}
+
+func TestModel_NotesMatchTruncatesByDisplayWidth(t *testing.T) {
+	m, tmpDir := createTestModel(t)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	task := Task{Description: "Call accountant", Notes: strings.Repeat("Traer los recibos del año pasado ", 3)}
+	m.searchQuery = "recibos"
+	m.searchAll = true
+
+	match := ansi.Strip(m.notesMatch(task))
+	if !utf8.ValidString(match) {
+		t.Fatalf("Expected the notes cut on a character boundary, got %q", match)
+	}
+	notes := strings.TrimPrefix(match, " — notes: ")
+	if lipgloss.Width(notes) != 40 || !strings.HasSuffix(notes, "...") {
+		t.Errorf("Expected the notes cut to 40 columns, got %q", notes)
+	}
+}